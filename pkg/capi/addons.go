@@ -0,0 +1,47 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sort"
+)
+
+// describeAddons describes the state of each add-on the control plane reports, ordered by add-on name
+func describeAddons(controlPlane *unstructured.Unstructured) []string {
+	if controlPlane == nil {
+		return nil
+	}
+	addonStatus, _, _ := unstructured.NestedMap(controlPlane.Object, "status", "addonStatus")
+	var names []string
+	for name := range addonStatus {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var states []string
+	for _, name := range names {
+		status, ok := addonStatus[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		states = append(states, describeAddon(name, status))
+	}
+	return states
+}
+
+func describeAddon(name string, status map[string]interface{}) string {
+	msg := "add-on " + name
+	if state, _, _ := unstructured.NestedString(status, "lifecycleState"); state != "" {
+		msg = fmt.Sprintf("%s %s", msg, state)
+	}
+	if version, _, _ := unstructured.NestedString(status, "currentlyInstalledVersion"); version != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, version)
+	}
+	if message, _, _ := unstructured.NestedString(status, "addonError", "message"); message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, message)
+	}
+	return msg
+}
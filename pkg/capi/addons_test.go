@@ -0,0 +1,37 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func TestDescribeAddons(t *testing.T) {
+	controlPlane := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"addonStatus": map[string]interface{}{
+				"NativeIngressController": map[string]interface{}{
+					"lifecycleState": "NEEDS_ATTENTION",
+					"addonError": map[string]interface{}{
+						"code":    "InvalidConfiguration",
+						"message": "compartmentId is required",
+					},
+				},
+				"CertManager": map[string]interface{}{
+					"lifecycleState":            "ACTIVE",
+					"currentlyInstalledVersion": "v1.12.0",
+				},
+			},
+		},
+	}}
+
+	assert.Equal(t, []string{
+		"add-on CertManager ACTIVE (v1.12.0)",
+		"add-on NativeIngressController NEEDS_ATTENTION: compartmentId is required",
+	}, describeAddons(controlPlane))
+	assert.Empty(t, describeAddons(&unstructured.Unstructured{Object: map[string]interface{}{}}))
+	assert.Empty(t, describeAddons(nil))
+}
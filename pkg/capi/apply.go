@@ -0,0 +1,131 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FieldManager is the server-side apply field manager for objects applied by the driver
+const FieldManager = "kontainer-engine-driver-oke-capi"
+
+// legacyFieldManager owns the fields the driver set with updates, before it used server-side apply.
+// Kubernetes names update managers after the client's user agent, which defaults to the binary name.
+var legacyFieldManager = filepath.Base(os.Args[0])
+
+// applyObject server-side applies an object, so the driver only owns the fields its templates render.
+// live is the current object, or nil if the object does not exist yet.
+func applyObject(ctx context.Context, client dynamic.Interface, u, live *unstructured.Unstructured, rules []object.OwnershipRule) error {
+	groupVersionResource := object.GVR(u)
+	if live != nil {
+		if err := applyOwnershipRules(u, live, rules); err != nil {
+			return fmt.Errorf("ownership rules failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
+		}
+	}
+	_, err := client.Resource(groupVersionResource).Namespace(object.DefaultingNamespace(u)).Apply(ctx, u.GetName(), u, metav1.ApplyOptions{
+		FieldManager: FieldManager,
+		// take over the fields the driver set before it used server-side apply
+		Force: live != nil && hasLegacyFields(live),
+	})
+	if err != nil {
+		if conflicts := fieldConflicts(err); len(conflicts) > 0 {
+			return fmt.Errorf("apply conflicts %s/%s/%s %s: %s", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, u.GetName(), strings.Join(conflicts, ", "))
+		}
+		return fmt.Errorf("apply failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
+	}
+	return nil
+}
+
+// applyOwnershipRules sets create-only fields of the applied object to their live values.
+// Only the fields the template rendered are kept, so fields set by controllers, such as the IDs CAPOCI sets, stay theirs.
+func applyOwnershipRules(u, live *unstructured.Unstructured, rules []object.OwnershipRule) error {
+	for _, rule := range rules {
+		if !rule.CreateOnly {
+			continue
+		}
+		renderedValue, found, err := unstructured.NestedFieldNoCopy(u.Object, rule.Path...)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		liveValue, found, err := unstructured.NestedFieldCopy(live.Object, rule.Path...)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if err := unstructured.SetNestedField(u.Object, renderedFields(liveValue, renderedValue), rule.Path...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderedFields limits a live value to the fields of the rendered value. List items are matched by position.
+func renderedFields(live, rendered interface{}) interface{} {
+	switch renderedValue := rendered.(type) {
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		res := map[string]interface{}{}
+		for key, value := range renderedValue {
+			if liveField, ok := liveValue[key]; ok {
+				res[key] = renderedFields(liveField, value)
+			}
+		}
+		return res
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+		res := []interface{}{}
+		for i := 0; i < len(liveValue) && i < len(renderedValue); i++ {
+			res = append(res, renderedFields(liveValue[i], renderedValue[i]))
+		}
+		return res
+	default:
+		return live
+	}
+}
+
+// hasLegacyFields is true if the legacy field manager still owns fields of the object
+func hasLegacyFields(live *unstructured.Unstructured) bool {
+	for _, managedFields := range live.GetManagedFields() {
+		if managedFields.Manager == legacyFieldManager && managedFields.Operation == metav1.ManagedFieldsOperationUpdate {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldConflicts describes each field an apply conflicted on, and its owner
+func fieldConflicts(err error) []string {
+	var statusErr *apierrors.StatusError
+	if !apierrors.IsConflict(err) || !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return nil
+	}
+	var conflicts []string
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", cause.Field, cause.Message))
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"strings"
+	"testing"
+)
+
+func TestApplyOwnershipRules(t *testing.T) {
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"networkSpec": map[string]interface{}{"vcn": map[string]interface{}{
+				"cidr":    "10.0.0.0/16",
+				"subnets": []interface{}{map[string]interface{}{"name": "worker", "cidr": "10.0.10.0/24"}},
+			}},
+			"compartmentId": "new",
+		},
+	}}
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"networkSpec": map[string]interface{}{"vcn": map[string]interface{}{
+				"cidr":    "10.1.0.0/16",
+				"id":      "vcn",
+				"subnets": []interface{}{map[string]interface{}{"name": "worker", "cidr": "10.1.10.0/24", "id": "subnet"}},
+			}},
+			"compartmentId": "old",
+			"missing":       "live",
+		},
+	}}
+	rules := []object.OwnershipRule{
+		{Path: []string{"spec", "networkSpec"}, CreateOnly: true},
+		{Path: []string{"spec", "missing"}, CreateOnly: true},
+	}
+	assert.NoError(t, applyOwnershipRules(desired, live, rules))
+
+	// create-only fields keep their live value, and other fields are applied
+	cidr, _, _ := unstructured.NestedString(desired.Object, "spec", "networkSpec", "vcn", "cidr")
+	assert.Equal(t, "10.1.0.0/16", cidr)
+	subnets, _, _ := unstructured.NestedSlice(desired.Object, "spec", "networkSpec", "vcn", "subnets")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "worker", "cidr": "10.1.10.0/24"}}, subnets)
+	// fields set by CAPOCI are not applied
+	_, found, _ := unstructured.NestedFieldNoCopy(desired.Object, "spec", "networkSpec", "vcn", "id")
+	assert.False(t, found)
+	compartmentId, _, _ := unstructured.NestedString(desired.Object, "spec", "compartmentId")
+	assert.Equal(t, "new", compartmentId)
+	_, found, _ = unstructured.NestedFieldNoCopy(desired.Object, "spec", "missing")
+	assert.False(t, found)
+}
+
+func TestHasLegacyFields(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply},
+	})
+	assert.False(t, hasLegacyFields(u))
+	u.SetManagedFields(append(u.GetManagedFields(), metav1.ManagedFieldsEntry{
+		Manager:   legacyFieldManager,
+		Operation: metav1.ManagedFieldsOperationUpdate,
+	}))
+	assert.True(t, hasLegacyFields(u))
+}
+
+// TestHasLegacyFieldsFromUpdate checks an object the driver updated before it used server-side apply.
+// The API server names update managers after the client's user agent, up to the first "/".
+func TestHasLegacyFieldsFromUpdate(t *testing.T) {
+	manager := strings.Split(rest.DefaultKubernetesUserAgent(), "/")[0]
+	live := &unstructured.Unstructured{}
+	assert.NoError(t, live.UnmarshalJSON([]byte(fmt.Sprintf(`{
+	"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta2",
+	"kind": "OCIManagedCluster",
+	"metadata": {
+		"name": "test",
+		"namespace": "test",
+		"managedFields": [
+			{
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta2",
+				"fieldsType": "FieldsV1",
+				"fieldsV1": {"f:spec": {"f:compartmentId": {}, "f:networkSpec": {".": {}}}},
+				"manager": %q,
+				"operation": "Update",
+				"time": "2023-05-01T00:00:00Z"
+			},
+			{
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta2",
+				"fieldsType": "FieldsV1",
+				"fieldsV1": {"f:status": {"f:ready": {}}},
+				"manager": "cluster-api-provider-oci-manager",
+				"operation": "Update",
+				"subresource": "status",
+				"time": "2023-05-01T00:05:00Z"
+			}
+		]
+	},
+	"spec": {"compartmentId": "test"}
+}`, manager))))
+	assert.True(t, hasLegacyFields(live))
+
+	live.SetManagedFields(live.GetManagedFields()[1:])
+	assert.False(t, hasLegacyFields(live))
+}
+
+func TestApplyConflicts(t *testing.T) {
+	di := createTestDI()
+	di.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		err := apierrors.NewApplyConflict([]metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Field:   ".spec.replicas",
+				Message: "conflict with \"cluster-autoscaler\"",
+			},
+		}, "Apply failed with 1 conflict")
+		return true, nil, err
+	})
+	_, err := createOrUpdateObject(context.TODO(), di, object.CAPICluster, testVariables)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ".spec.replicas (conflict with \"cluster-autoscaler\")")
+}
+
+func TestApplyCreatesAndUpdates(t *testing.T) {
+	ctx := context.TODO()
+	di := createTestDI()
+	_, err := createOrUpdateObject(ctx, di, object.CAPICluster, testVariables)
+	assert.NoError(t, err)
+	_, err = di.Resource(gvr.Cluster).Namespace(testName).Get(ctx, testName, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	v := *testVariables
+	v.PodCIDR = "10.244.0.0/16"
+	_, err = createOrUpdateObject(ctx, di, object.CAPICluster, &v)
+	assert.NoError(t, err)
+	cluster, err := di.Resource(gvr.Cluster).Namespace(testName).Get(ctx, testName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	cidrs, _, _ := unstructured.NestedStringSlice(cluster.Object, "spec", "clusterNetwork", "pods", "cidrBlocks")
+	assert.Equal(t, []string{"10.244.0.0/16"}, cidrs)
+
+	// every apply is a server-side apply
+	for _, action := range di.Actions() {
+		assert.NotEqual(t, "update", action.GetVerb())
+		assert.NotEqual(t, "create", action.GetVerb())
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"fmt"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	"k8s.io/client-go/dynamic"
+)
+
+// CreateOrDeleteClusterAutoscaler deploys the cluster autoscaler on the admin cluster if any node pool is autoscaled,
+// and removes it otherwise
+func (c *CAPIClient) CreateOrDeleteClusterAutoscaler(ctx context.Context, di dynamic.Interface, v *variables.Variables) error {
+	if v.IsAutoscaled() {
+		if _, err := createOrUpdateObject(ctx, di, object.ClusterAutoscaler, v); err != nil {
+			return fmt.Errorf("failed to deploy cluster autoscaler: %v", err)
+		}
+		return nil
+	}
+	us, err := object.LoadTextTemplate(object.ClusterAutoscaler, *v)
+	if err != nil {
+		return err
+	}
+	// delete in reverse, so the autoscaler is stopped before its permissions are removed
+	for idx := len(us) - 1; idx >= 0; idx-- {
+		u := &us[idx]
+		if err := deleteIfExists(ctx, di, object.GVR(u), u.GetName(), object.DefaultingNamespace(u)); err != nil {
+			return fmt.Errorf("failed to delete cluster autoscaler: %v", err)
+		}
+	}
+	return nil
+}
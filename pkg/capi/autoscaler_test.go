@@ -0,0 +1,76 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fake2 "k8s.io/client-go/dynamic/fake"
+	"testing"
+)
+
+const (
+	autoscalerMinSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"
+	autoscalerMaxSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"
+)
+
+var deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func autoscaledVariables() *variables.Variables {
+	v := *testVariables
+	v.ClusterAutoscalerImage = variables.DefaultClusterAutoscalerImage
+	v.NodePools = []variables.NodePool{
+		{Name: "np-1", Replicas: 1},
+		{Name: "np-2", Replicas: 2, MinReplicas: 1, MaxReplicas: 5},
+	}
+	return &v
+}
+
+func TestRenderAutoscaledMachinePools(t *testing.T) {
+	us, err := object.LoadTextTemplate(object.Workers[0], *autoscaledVariables())
+	assert.NoError(t, err)
+	assert.Len(t, us, 2)
+
+	// fixed size pool
+	_, found, _ := unstructured.NestedInt64(us[0].Object, "spec", "replicas")
+	assert.True(t, found)
+	assert.Empty(t, us[0].GetAnnotations())
+
+	// autoscaled pool
+	_, found, _ = unstructured.NestedInt64(us[1].Object, "spec", "replicas")
+	assert.False(t, found)
+	assert.Equal(t, "1", us[1].GetAnnotations()[autoscalerMinSizeAnnotation])
+	assert.Equal(t, "5", us[1].GetAnnotations()[autoscalerMaxSizeAnnotation])
+}
+
+func TestCreateOrDeleteClusterAutoscaler(t *testing.T) {
+	ctx := context.TODO()
+	di := withApplyReactor(fake2.NewSimpleDynamicClient(runtime.NewScheme()))
+	v := autoscaledVariables()
+	name := testName + "-cluster-autoscaler"
+
+	assert.NoError(t, testCAPIClient.CreateOrDeleteClusterAutoscaler(ctx, di, v))
+	deployment, err := di.Resource(deploymentGVR).Namespace(testName).Get(ctx, name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	assert.Len(t, containers, 1)
+	assert.Equal(t, variables.DefaultClusterAutoscalerImage, containers[0].(map[string]interface{})["image"])
+
+	// updating is idempotent
+	assert.NoError(t, testCAPIClient.CreateOrDeleteClusterAutoscaler(ctx, di, v))
+
+	// the autoscaler is removed once no pool is autoscaled
+	v.NodePools[1].MaxReplicas = 0
+	assert.NoError(t, testCAPIClient.CreateOrDeleteClusterAutoscaler(ctx, di, v))
+	_, err = di.Resource(deploymentGVR).Namespace(testName).Get(ctx, name, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NoError(t, testCAPIClient.CreateOrDeleteClusterAutoscaler(ctx, di, v))
+}
@@ -33,14 +33,11 @@ const (
 	ociUseInstancePrincipalField = "useInstancePrincipal"
 )
 
-const (
-	clusterPhaseProvisioned = "Provisioned"
-	machinePoolPhaseRunning = "Running"
-)
-
 type CAPIClient struct {
 	verrazzanoTimeout         time.Duration
 	verrazzanoPollingInterval time.Duration
+	clusterReadyTimeout       time.Duration
+	rolloutPollingInterval    time.Duration
 	plog                      *provisioning.Logger
 }
 
@@ -48,6 +45,8 @@ func NewCAPIClient(plog *provisioning.Logger) *CAPIClient {
 	return &CAPIClient{
 		verrazzanoTimeout:         5 * time.Minute,
 		verrazzanoPollingInterval: 10 * time.Second,
+		clusterReadyTimeout:       30 * time.Minute,
+		rolloutPollingInterval:    30 * time.Second,
 		plog:                      plog,
 	}
 }
@@ -66,12 +65,55 @@ func (c *CAPIClient) CreateOrUpdateAllObjects(ctx context.Context, kubernetesInt
 	if err := createOrUpdateCAPISecret(ctx, v, kubernetesInterface); err != nil {
 		return nil, fmt.Errorf("failed to create CAPI credentials: %v", err)
 	}
-	return createOrUpdateObjects(ctx, dynamicInterface, object.CreateObjects(), v)
+	// node pools pending a rollout stay at their current version until the rollout upgrades them
+	pending, err := loadRollout(ctx, kubernetesInterface, v)
+	if err != nil {
+		return nil, err
+	}
+	pinned, err := pinNodePoolVersions(ctx, dynamicInterface, v, pending)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.warnPreemptibleNodePools(ctx, dynamicInterface, v); err != nil {
+		return nil, err
+	}
+	cruResult, err := createOrUpdateObjects(ctx, dynamicInterface, object.CreateObjects(), pinned)
+	if err != nil {
+		return cruResult, err
+	}
+	return cruResult, c.CreateOrDeleteClusterAutoscaler(ctx, dynamicInterface, v)
+}
+
+// warnPreemptibleNodePools warns that preempted nodes of new preemptible node pools are terminated, and not replaced
+func (c *CAPIClient) warnPreemptibleNodePools(ctx context.Context, di dynamic.Interface, v *variables.Variables) error {
+	for _, np := range v.NodePools {
+		if !np.Preemptible() {
+			continue
+		}
+		_, err := di.Resource(gvr.OCIMachinePools).Namespace(v.Namespace).Get(ctx, np.Name, metav1.GetOptions{})
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		_ = c.plog.Warnf("Creating preemptible node pool %s: preempted nodes are terminated and not replaced, so the pool may run with fewer than %d nodes", np.Name, np.Replicas)
+	}
+	return nil
 }
 
 // createOrUpdateCAPISecret creates the CAPI secret if it does not already exist
 // if the secret exists, update it in place with the new credentials
+// principals other than user principals have no secret, and any existing secret is removed
 func createOrUpdateCAPISecret(ctx context.Context, v *variables.Variables, client kubernetes.Interface) error {
+	secretName := fmt.Sprintf("%s-principal", v.Name)
+	if !v.UsesUserPrincipal() {
+		err := client.CoreV1().Secrets(v.Namespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
 	data := map[string][]byte{
 		ociTenancyField:              []byte(v.Tenancy),
 		ociUserField:                 []byte(v.User),
@@ -81,7 +123,6 @@ func createOrUpdateCAPISecret(ctx context.Context, v *variables.Variables, clien
 		ociKeyField:                  []byte(strings.TrimSpace(v.PrivateKey)),
 		ociUseInstancePrincipalField: []byte("false"),
 	}
-	secretName := fmt.Sprintf("%s-principal", v.Name)
 	current, err := client.CoreV1().Secrets(v.Namespace).Get(ctx, secretName, metav1.GetOptions{})
 	if err != nil {
 		// Create if not exists
@@ -118,12 +159,8 @@ func createOrUpdateObjects(ctx context.Context, dynamicInterface dynamic.Interfa
 	return cruResult, nil
 }
 
+// createOrUpdateObject create or update the objects rendered from a template using server-side apply
 func createOrUpdateObject(ctx context.Context, client dynamic.Interface, o object.Object, v *variables.Variables) (*CreateOrUpdateResult, error) {
-	return cruObject(ctx, client, o, v, func(u *unstructured.Unstructured) error { return nil })
-}
-
-// cruObject create or update an object
-func cruObject(ctx context.Context, client dynamic.Interface, o object.Object, v *variables.Variables, updater func(u *unstructured.Unstructured) error) (*CreateOrUpdateResult, error) {
 	cruResult := NewCreateOrUpdateResult()
 	toCreateObject, err := object.LoadTextTemplate(o, *v)
 	if err != nil {
@@ -132,44 +169,27 @@ func cruObject(ctx context.Context, client dynamic.Interface, o object.Object, v
 
 	for idx := range toCreateObject {
 		u := &toCreateObject[idx]
-		// Try to fetch existing object
-		groupVersionResource := object.GVR(u)
-		existingObject, err := client.Resource(groupVersionResource).Namespace(object.DefaultingNamespace(u)).Get(ctx, u.GetName(), metav1.GetOptions{})
-		if err != nil {
-			// if object doesn't exist, try to create it
-			if apierrors.IsNotFound(err) {
-				if err := createIfNotExists(ctx, client, u); err != nil {
-					return cruResult, fmt.Errorf("create failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
-				}
-			} else {
-				return cruResult, fmt.Errorf("get failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
-			}
-		} else { // If the Object exists, merge with existingObject and do an update
-			mergedObject := mergeUnstructured(existingObject, u, o.LockedFields)
-			if err != nil {
-				return cruResult, fmt.Errorf("merge failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
-			}
-			if err := updater(mergedObject); err != nil {
-				return cruResult, fmt.Errorf("spec update failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
-			}
-			_, err = client.Resource(groupVersionResource).Namespace(object.DefaultingNamespace(mergedObject)).Update(context.TODO(), mergedObject, metav1.UpdateOptions{})
-			if err != nil {
-				return cruResult, fmt.Errorf("update failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
-			}
+		if err := createOrUpdateUnstructured(ctx, client, u, o.OwnershipRules); err != nil {
+			return cruResult, err
 		}
-
-		cruResult.Add(groupVersionResource.Resource, u)
+		cruResult.Add(object.GVR(u).Resource, u)
 	}
 
 	return cruResult, nil
 }
 
-func createIfNotExists(ctx context.Context, client dynamic.Interface, u *unstructured.Unstructured) error {
-	_, err := client.Resource(object.GVR(u)).Namespace(object.DefaultingNamespace(u)).Create(ctx, u, metav1.CreateOptions{})
-	if apierrors.IsAlreadyExists(err) {
-		return nil
+// createOrUpdateUnstructured create or update a rendered object using server-side apply
+func createOrUpdateUnstructured(ctx context.Context, client dynamic.Interface, u *unstructured.Unstructured, rules []object.OwnershipRule) error {
+	groupVersionResource := object.GVR(u)
+	// Fetch the live object, if it exists, for ownership rules
+	live, err := client.Resource(groupVersionResource).Namespace(object.DefaultingNamespace(u)).Get(ctx, u.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("get failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
+		}
+		live = nil
 	}
-	return err
+	return applyObject(ctx, client, u, live, rules)
 }
 
 // DeleteCluster deletes the cluster
@@ -264,10 +284,8 @@ func deleteWorkerObjects(ctx context.Context, di dynamic.Interface, namespace st
 }
 
 func deleteIfNotCRU(ctx context.Context, di dynamic.Interface, v *variables.Variables, u *unstructured.Unstructured) (bool, error) {
-	for _, np := range v.NodePools {
-		if np.Name == u.GetName() {
-			return false, nil
-		}
+	if findNodePool(v, u.GetName()) {
+		return false, nil
 	}
 	return true, deleteUnstructureds(ctx, di, []unstructured.Unstructured{*u})
 }
@@ -13,19 +13,29 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning"
 	fakelogger "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning/fake"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/region"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/templates"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/version"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	fake2 "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 const (
 	testName = "test"
 
+	clusterPhaseProvisioned = "Provisioned"
+
 	testMachine = `apiVersion: cluster.x-k8s.io/v1beta1
 kind: MachinePool
 metadata:
@@ -41,10 +51,11 @@ ddd`
 )
 
 var (
-	testCAPIClient = &CAPIClient{}
+	testCAPIClient = NewCAPIClient(fakelogger.NewLogger())
 
 	testVariables = &variables.Variables{
 		Name:              testName,
+		DisplayName:       testName,
 		Namespace:         testName,
 		CloudCredentialId: "cattle-global-data:admin-creds",
 		Tenancy:           "t",
@@ -68,6 +79,29 @@ func TestCreateOrUpdateAllObjects(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateOrUpdateCAPISecret(t *testing.T) {
+	ctx := context.TODO()
+	ki := fake.NewSimpleClientset()
+	secretName := testName + "-principal"
+	assert.NoError(t, createOrUpdateCAPISecret(ctx, testVariables, ki))
+	_, err := ki.CoreV1().Secrets(testName).Get(ctx, secretName, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// switching to instance principals removes the principal secret
+	v := *testVariables
+	v.AuthType = variables.AuthTypeInstancePrincipal
+	assert.NoError(t, createOrUpdateCAPISecret(ctx, &v, ki))
+	_, err = ki.CoreV1().Secrets(testName).Get(ctx, secretName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	us, err := object.LoadTextTemplate(object.Object{Text: templates.ClusterIdentity}, v)
+	assert.NoError(t, err)
+	principalType, _, _ := unstructured.NestedString(us[0].Object, "spec", "type")
+	assert.Equal(t, variables.AuthTypeInstancePrincipal, principalType)
+	_, found, _ := unstructured.NestedMap(us[0].Object, "spec", "principalSecret")
+	assert.False(t, found)
+}
+
 func TestRenderObjects(t *testing.T) {
 	v := variables.Variables{
 		DisplayName:          "xyz",
@@ -119,6 +153,341 @@ func TestRenderObjects(t *testing.T) {
 	}
 }
 
+func TestRenderPrivateEndpoint(t *testing.T) {
+	v := *testVariables
+	v.QuickCreateVCN = true
+	v.CNIType = "FLANNEL_OVERLAY"
+	v.PrivateEndpoint = true
+	network, err := variables.PlanNetwork(variables.DefaultVCNCIDR)
+	assert.NoError(t, err)
+	v.Network = network
+	v.RegionInfo, err = region.Lookup("eu-frankfurt-1")
+	assert.NoError(t, err)
+
+	us, err := object.LoadTextTemplate(object.Object{Text: templates.OCIManagedCluster}, v)
+	assert.NoError(t, err)
+	subnets, _, _ := unstructured.NestedSlice(us[0].Object, "spec", "networkSpec", "vcn", "subnets")
+	assert.Equal(t, "private", subnets[0].(map[string]interface{})["type"])
+	nsgs, _, _ := unstructured.NestedSlice(us[0].Object, "spec", "networkSpec", "vcn", "networkSecurityGroup", "list")
+	for _, rule := range nsgs[0].(map[string]interface{})["ingressRules"].([]interface{}) {
+		source, _, _ := unstructured.NestedString(rule.(map[string]interface{}), "ingressRule", "source")
+		assert.NotEqual(t, "0.0.0.0/0", source)
+	}
+
+	egress, _ := nsgs[0].(map[string]interface{})["egressRules"].([]interface{})
+	destination, _, _ := unstructured.NestedString(egress[0].(map[string]interface{}), "egressRule", "destination")
+	assert.Equal(t, "all-fra-services-in-oracle-services-network", destination)
+
+	us, err = object.LoadTextTemplate(object.ControlPlane[0], v)
+	assert.NoError(t, err)
+	public, found, _ := unstructured.NestedBool(us[0].Object, "spec", "endpointConfig", "isPublicIpEnabled")
+	assert.True(t, found)
+	assert.False(t, public)
+}
+
+func TestRenderQuickCreateNetworkSecurityGroups(t *testing.T) {
+	network, err := variables.PlanNetwork(variables.DefaultVCNCIDR)
+	assert.NoError(t, err)
+	r, err := region.Lookup("us-phoenix-1")
+	assert.NoError(t, err)
+	var tests = []struct {
+		cniType string
+		roles   []string
+	}{
+		{
+			"FLANNEL_OVERLAY",
+			[]string{"control-plane-endpoint", "worker", "service-lb"},
+		},
+		{
+			"OCI_VCN_IP_NATIVE",
+			[]string{"control-plane-endpoint", "worker", "pod", "service-lb"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cniType, func(t *testing.T) {
+			v := *testVariables
+			v.QuickCreateVCN = true
+			v.CNIType = tt.cniType
+			v.Network = network
+			v.RegionInfo = r
+			us, err := object.LoadTextTemplate(object.Object{Text: templates.OCIManagedCluster}, v)
+			assert.NoError(t, err)
+
+			subnets, _, _ := unstructured.NestedSlice(us[0].Object, "spec", "networkSpec", "vcn", "subnets")
+			nsgs, _, _ := unstructured.NestedSlice(us[0].Object, "spec", "networkSpec", "vcn", "networkSecurityGroup", "list")
+			var roles []string
+			for _, nsg := range nsgs {
+				roles = append(roles, nsg.(map[string]interface{})["role"].(string))
+			}
+			assert.Equal(t, tt.roles, roles)
+			// every NSG role has a subnet
+			assert.Len(t, subnets, len(roles))
+			// pods reach the internet through the NAT gateway
+			for _, nsg := range nsgs {
+				if nsg.(map[string]interface{})["role"] != "pod" {
+					continue
+				}
+				assert.Contains(t, nsg.(map[string]interface{})["egressRules"], map[string]interface{}{
+					"egressRule": map[string]interface{}{
+						"description":     "Allow pods to communicate with the internet.",
+						"destination":     "0.0.0.0/0",
+						"destinationType": "CIDR_BLOCK",
+						"isStateless":     false,
+						"protocol":        "6",
+					},
+				})
+			}
+		})
+	}
+}
+
+func TestRenderNodePoolPlacement(t *testing.T) {
+	v := *testVariables
+	v.CNIType = "OCI_VCN_IP_NATIVE"
+	v.AvailabilityDomains = []string{"Uocm:PHX-AD-1", "Uocm:PHX-AD-2"}
+	v.Subnets = []variables.Subnet{{Id: "ocid1.subnet.oc1.phx.pool", Name: "worker-np-2", Role: "worker"}}
+	v.NetworkSecurityGroups = []variables.NetworkSecurityGroup{{Id: "ocid1.networksecuritygroup.oc1.phx.nsg", Name: "worker-nsg-1", Role: "worker"}}
+	v.NodePools = []variables.NodePool{
+		{Name: "np-1", Replicas: 1},
+		{
+			Name:                "np-2",
+			Replicas:            1,
+			AvailabilityDomains: []string{"Uocm:PHX-AD-2"},
+			FaultDomains:        []string{"FAULT-DOMAIN-1", "FAULT-DOMAIN-2"},
+			Subnet:              "ocid1.subnet.oc1.phx.pool",
+			NSGs:                []string{"ocid1.networksecuritygroup.oc1.phx.nsg"},
+		},
+	}
+
+	us, err := object.LoadTextTemplate(object.Workers[1], v)
+	assert.NoError(t, err)
+	assert.Len(t, us, 2)
+
+	// pools without placement are spread across every availability domain, in the cluster's worker subnet
+	placements, _, _ := unstructured.NestedSlice(us[0].Object, "spec", "nodePoolNodeConfig", "placementConfigs")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"availabilityDomain": "Uocm:PHX-AD-1", "subnetName": "worker"},
+		map[string]interface{}{"availabilityDomain": "Uocm:PHX-AD-2", "subnetName": "worker"},
+	}, placements)
+	_, found, _ := unstructured.NestedSlice(us[0].Object, "spec", "nodePoolNodeConfig", "nsgNames")
+	assert.False(t, found)
+
+	placements, _, _ = unstructured.NestedSlice(us[1].Object, "spec", "nodePoolNodeConfig", "placementConfigs")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"availabilityDomain": "Uocm:PHX-AD-2",
+			"subnetName":         "worker-np-2",
+			"faultDomains":       []interface{}{"FAULT-DOMAIN-1", "FAULT-DOMAIN-2"},
+		},
+	}, placements)
+	nsgNames, _, _ := unstructured.NestedStringSlice(us[1].Object, "spec", "nodePoolNodeConfig", "nsgNames")
+	assert.Equal(t, []string{"worker-nsg-1"}, nsgNames)
+
+	// existing network security groups are referenced by the cluster
+	us, err = object.LoadTextTemplate(object.Object{Text: templates.OCIManagedCluster}, v)
+	assert.NoError(t, err)
+	nsgs, _, _ := unstructured.NestedSlice(us[0].Object, "spec", "networkSpec", "vcn", "networkSecurityGroup", "list")
+	assert.Len(t, nsgs, 1)
+}
+
+func TestRenderNodeLabelsAndTaints(t *testing.T) {
+	v := *testVariables
+	v.NodePools = []variables.NodePool{
+		{Name: "np-1", Replicas: 1},
+		{
+			Name:     "gpu",
+			Replicas: 1,
+			Labels:   map[string]string{"workload": "gpu", "accelerator": "a10"},
+			Taints:   []variables.Taint{{Key: "nvidia.com/gpu", Effect: variables.TaintEffectNoSchedule}},
+		},
+	}
+
+	us, err := object.LoadTextTemplate(object.Workers[1], v)
+	assert.NoError(t, err)
+	_, found, _ := unstructured.NestedSlice(us[0].Object, "spec", "initialNodeLabels")
+	assert.False(t, found)
+	_, found, _ = unstructured.NestedMap(us[0].Object, "spec", "nodeMetadata")
+	assert.False(t, found)
+
+	labels, _, _ := unstructured.NestedSlice(us[1].Object, "spec", "initialNodeLabels")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "accelerator", "value": "a10"},
+		map[string]interface{}{"key": "workload", "value": "gpu"},
+	}, labels)
+	userData, _, _ := unstructured.NestedString(us[1].Object, "spec", "nodeMetadata", "user_data")
+	assert.Equal(t, v.NodePools[1].UserData(), userData)
+}
+
+func TestRenderNodePoolCapacity(t *testing.T) {
+	v := *testVariables
+	v.AvailabilityDomains = []string{"Uocm:PHX-AD-1"}
+	v.NodePools = []variables.NodePool{
+		{Name: "stable", Replicas: 1},
+		{Name: "ci", Replicas: 1, CapacityType: variables.CapacityTypePreemptible},
+		{Name: "reserved", Replicas: 1, CapacityType: variables.CapacityTypeReserved, CapacityReservationID: "ocid1.capacityreservation.oc1.phx.aaaa"},
+	}
+
+	us, err := object.LoadTextTemplate(object.Workers[1], v)
+	assert.NoError(t, err)
+	placement := func(u unstructured.Unstructured) map[string]interface{} {
+		placements, _, _ := unstructured.NestedSlice(u.Object, "spec", "nodePoolNodeConfig", "placementConfigs")
+		return placements[0].(map[string]interface{})
+	}
+	assert.NotContains(t, placement(us[0]), "preemptibleNodeConfig")
+	assert.NotContains(t, placement(us[0]), "capacityReservationId")
+
+	preserve, found, _ := unstructured.NestedBool(placement(us[1]), "preemptibleNodeConfig", "preemptionAction", "isPreserveBootVolume")
+	assert.True(t, found)
+	assert.False(t, preserve)
+
+	assert.Equal(t, "ocid1.capacityreservation.oc1.phx.aaaa", placement(us[2])["capacityReservationId"])
+}
+
+func TestWarnPreemptibleNodePools(t *testing.T) {
+	ctx := context.TODO()
+	ki := fake.NewSimpleClientset()
+	c := NewCAPIClient(provisioning.NewLogger(ctx, ki, testName))
+	v := *testVariables
+	v.NodePools = []variables.NodePool{
+		{Name: "batch", Replicas: 3, CapacityType: variables.CapacityTypePreemptible},
+		{Name: "ci", Replicas: 2, CapacityType: variables.CapacityTypePreemptible},
+		{Name: "default", Replicas: 1},
+	}
+	// ci already exists, and was warned about when it was created
+	di := createTestDI(createTestOCIMachinePool("ci", "ocid1.nodepool.oc1.phx.ci"))
+	assert.NoError(t, c.warnPreemptibleNodePools(ctx, di, &v))
+
+	cm, err := ki.CoreV1().ConfigMaps(testName).Get(ctx, "provisioning-log", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, cm.Data["log"], "[WARN] Creating preemptible node pool batch: preempted nodes are terminated and not replaced")
+	assert.NotContains(t, cm.Data["log"], "node pool ci")
+	assert.NotContains(t, cm.Data["log"], "node pool default")
+}
+
+func TestRenderEncryption(t *testing.T) {
+	v := *testVariables
+	v.KMSKeyID = "ocid1.key.oc1.phx.secrets"
+	v.NodePVTransitEncryption = true
+	v.NodePools = []variables.NodePool{
+		{Name: "default", Replicas: 1},
+		{Name: "encrypted", Replicas: 1, BootVolumeKMSKeyID: "ocid1.key.oc1.phx.boot"},
+	}
+
+	cp, err := object.LoadTextTemplate(object.ControlPlane[0], v)
+	assert.NoError(t, err)
+	kmsKeyId, _, _ := unstructured.NestedString(cp[0].Object, "spec", "kmsKeyId")
+	assert.Equal(t, "ocid1.key.oc1.phx.secrets", kmsKeyId)
+
+	us, err := object.LoadTextTemplate(object.Workers[1], v)
+	assert.NoError(t, err)
+	for _, u := range us {
+		transit, _, _ := unstructured.NestedBool(u.Object, "spec", "nodePoolNodeConfig", "isPvEncryptionInTransitEnabled")
+		assert.True(t, transit)
+	}
+	_, found, _ := unstructured.NestedString(us[0].Object, "spec", "nodePoolNodeConfig", "kmsKeyId")
+	assert.False(t, found)
+	kmsKeyId, _, _ = unstructured.NestedString(us[1].Object, "spec", "nodePoolNodeConfig", "kmsKeyId")
+	assert.Equal(t, "ocid1.key.oc1.phx.boot", kmsKeyId)
+}
+
+func TestRenderTags(t *testing.T) {
+	v := *testVariables
+	v.FreeformTags = map[string]string{"team": "platform"}
+	v.DefinedTags = map[string]map[string]string{"Operations": {"CostCenter": "42"}}
+
+	us, err := object.LoadTextTemplate(object.Object{Text: templates.OCIManagedCluster}, v)
+	assert.NoError(t, err)
+	freeformTags, _, _ := unstructured.NestedStringMap(us[0].Object, "spec", "freeformTags")
+	assert.Equal(t, map[string]string{
+		"team":                          "platform",
+		variables.RancherClusterIDTag:   v.Name,
+		variables.RancherClusterNameTag: v.DisplayName,
+		variables.DriverVersionTag:      version.DriverVersion,
+	}, freeformTags)
+	definedTags, _, _ := unstructured.NestedMap(us[0].Object, "spec", "definedTags")
+	assert.Equal(t, map[string]interface{}{
+		"Operations": map[string]interface{}{"CostCenter": "42"},
+	}, definedTags)
+}
+
+func TestRenderImagePolicy(t *testing.T) {
+	v := *testVariables
+	cp, err := object.LoadTextTemplate(object.ControlPlane[0], v)
+	assert.NoError(t, err)
+	enabled, found, _ := unstructured.NestedBool(cp[0].Object, "spec", "imagePolicyConfig", "isPolicyEnabled")
+	assert.True(t, found)
+	assert.False(t, enabled)
+	_, found, _ = unstructured.NestedSlice(cp[0].Object, "spec", "imagePolicyConfig", "keyDetails")
+	assert.False(t, found)
+
+	v.ImageVerification = true
+	v.ImageVerificationKMSKeyIDs = []string{"ocid1.key.oc1.phx.signing1", "ocid1.key.oc1.phx.signing2"}
+	cp, err = object.LoadTextTemplate(object.ControlPlane[0], v)
+	assert.NoError(t, err)
+	enabled, _, _ = unstructured.NestedBool(cp[0].Object, "spec", "imagePolicyConfig", "isPolicyEnabled")
+	assert.True(t, enabled)
+	keyDetails, _, _ := unstructured.NestedSlice(cp[0].Object, "spec", "imagePolicyConfig", "keyDetails")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"kmsKeyId": "ocid1.key.oc1.phx.signing1"},
+		map[string]interface{}{"kmsKeyId": "ocid1.key.oc1.phx.signing2"},
+	}, keyDetails)
+}
+
+func TestRenderAddons(t *testing.T) {
+	v := *testVariables
+	cp, err := object.LoadTextTemplate(object.ControlPlane[0], v)
+	assert.NoError(t, err)
+	_, found, _ := unstructured.NestedSlice(cp[0].Object, "spec", "addons")
+	assert.False(t, found)
+
+	v.Addons = []variables.Addon{
+		{Name: "CertManager"},
+		{
+			Name:    "CoreDNS",
+			Version: "v1.10.1",
+			Configurations: map[string]string{
+				"nodesPerReplica":           "4",
+				"customizeCoreDNSConfigMap": `{"Corefile": ".:53 {}"}`,
+			},
+		},
+	}
+	cp, err = object.LoadTextTemplate(object.ControlPlane[0], v)
+	assert.NoError(t, err)
+	addons, _, _ := unstructured.NestedSlice(cp[0].Object, "spec", "addons")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"name": "CertManager",
+		},
+		map[string]interface{}{
+			"name":    "CoreDNS",
+			"version": "v1.10.1",
+			"configurations": []interface{}{
+				map[string]interface{}{"key": "customizeCoreDNSConfigMap", "value": `{"Corefile": ".:53 {}"}`},
+				map[string]interface{}{"key": "nodesPerReplica", "value": "4"},
+			},
+		},
+	}, addons)
+}
+
+func TestRenderNodePoolImages(t *testing.T) {
+	v := *testVariables
+	v.ActualImage = "ocid1.image.oc1.phx.cluster"
+	v.NodePools = []variables.NodePool{
+		{Name: "x86", Replicas: 1},
+		{Name: "arm", Replicas: 1, Shape: "VM.Standard.A1.Flex", ActualImage: "ocid1.image.oc1.phx.arm"},
+	}
+
+	us, err := object.LoadTextTemplate(object.Workers[1], v)
+	assert.NoError(t, err)
+	imageId := func(u unstructured.Unstructured) string {
+		id, _, _ := unstructured.NestedString(u.Object, "spec", "nodeSourceViaImage", "imageId")
+		return id
+	}
+	assert.Equal(t, "ocid1.image.oc1.phx.cluster", imageId(us[0]))
+	assert.Equal(t, "ocid1.image.oc1.phx.arm", imageId(us[1]))
+}
+
 func TestDeleteCluster(t *testing.T) {
 	cluster := createTestCluster(testVariables, true, true, clusterPhaseProvisioned)
 	ki := fake.NewSimpleClientset()
@@ -152,12 +521,6 @@ func TestDeleteCluster(t *testing.T) {
 	}
 }
 
-func listGVK(u *unstructured.Unstructured) schema.GroupVersionKind {
-	gvk := u.GroupVersionKind()
-	gvk.Kind = gvk.Kind + "List"
-	return gvk
-}
-
 func createTestMachine(v *variables.Variables, phase string) *unstructured.Unstructured {
 	machine, err := object.LoadTextTemplate(object.Object{
 		Text: testMachine,
@@ -181,20 +544,93 @@ func createTestCluster(v *variables.Variables, cReady, iReady bool, phase string
 		"controlPlaneReady":   cReady,
 		"infrastructureReady": iReady,
 		"phase":               phase,
+		"conditions":          []interface{}{testReadyCondition(cReady && iReady)},
 	}
 	return &cluster[0]
 }
 
+func createTestControlPlane(v *variables.Variables, ready bool) *unstructured.Unstructured {
+	controlPlane, err := object.LoadTextTemplate(object.ControlPlane[0], *v)
+	if err != nil {
+		panic(err)
+	}
+	controlPlane[0].Object["status"] = map[string]interface{}{
+		"ready":      ready,
+		"conditions": []interface{}{testReadyCondition(ready)},
+	}
+	return &controlPlane[0]
+}
+
+func testReadyCondition(ready bool) map[string]interface{} {
+	if ready {
+		return map[string]interface{}{
+			"type":   "Ready",
+			"status": "True",
+		}
+	}
+	return map[string]interface{}{
+		"type":     "Ready",
+		"status":   "False",
+		"reason":   "WaitingForInfrastructure",
+		"severity": "Info",
+	}
+}
+
+// createTestOCIMachinePool creates an OCIManagedMachinePool, with the OCID of its node pool once the node pool is created
+func createTestOCIMachinePool(name, id string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta2",
+		"kind":       "OCIManagedMachinePool",
+		"spec":       map[string]interface{}{},
+	}}
+	u.SetName(name)
+	u.SetNamespace(testVariables.Namespace)
+	if id != "" {
+		_ = unstructured.SetNestedField(u.Object, id, "spec", "id")
+	}
+	return u
+}
+
 func createTestDIWithClusterAndMachine() dynamic.Interface {
 	cluster := createTestCluster(testVariables, true, true, clusterPhaseProvisioned)
+	controlPlane := createTestControlPlane(testVariables, true)
 	machine := createTestMachine(testVariables, machinePoolPhaseRunning)
-	scheme := runtime.NewScheme()
-	scheme.AddKnownTypeWithName(listGVK(machine), &unstructured.UnstructuredList{})
-	scheme.AddKnownTypeWithName(schema.GroupVersionKind{
-		Group:   gvr.MachinePool.Group,
-		Version: gvr.MachinePool.Version,
-		Kind:    "MachinePoolList",
-	}, &unstructured.UnstructuredList{})
-	di := fake2.NewSimpleDynamicClient(scheme, cluster, machine)
+	return createTestDI(cluster, controlPlane, machine)
+}
+
+// createTestDI creates a fake dynamic client that can list the cluster's CAPI objects
+func createTestDI(objects ...runtime.Object) *fake2.FakeDynamicClient {
+	return withApplyReactor(fake2.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gvr.Cluster:         "ClusterList",
+		gvr.OCIControlPlane: "OCIManagedControlPlaneList",
+		gvr.MachinePool:     "MachinePoolList",
+		gvr.OCIMachinePools: "OCIManagedMachinePoolList",
+	}, objects...))
+}
+
+// withApplyReactor handles server-side apply, which the fake dynamic client does not support.
+// Applied objects replace the live object's spec and metadata, and keep its status.
+func withApplyReactor(di *fake2.FakeDynamicClient) *fake2.FakeDynamicClient {
+	di.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch, ok := action.(k8stesting.PatchAction)
+		if !ok || patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		live, err := di.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if apierrors.IsNotFound(err) {
+			return true, u, di.Tracker().Create(patch.GetResource(), u, patch.GetNamespace())
+		}
+		if err != nil {
+			return true, nil, err
+		}
+		if status, ok := live.(*unstructured.Unstructured).Object["status"]; ok {
+			u.Object["status"] = status
+		}
+		return true, u, di.Tracker().Update(patch.GetResource(), u, patch.GetNamespace())
+	})
 	return di
 }
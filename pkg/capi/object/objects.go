@@ -95,8 +95,17 @@ func objectList(i include) []Object {
 }
 
 type Object struct {
-	Text         string
-	LockedFields map[string]bool
+	Text string
+	// OwnershipRules limit the driver's ownership of the rendered object's fields
+	OwnershipRules []OwnershipRule
+}
+
+// OwnershipRule limits the driver's ownership of a field
+type OwnershipRule struct {
+	// Path to the field, e.g. spec.networkSpec is {"spec", "networkSpec"}
+	Path []string
+	// CreateOnly fields are set when the object is created, and afterwards keep the live value of the fields the template renders
+	CreateOnly bool
 }
 
 type include struct {
@@ -119,10 +128,20 @@ var capi = []Object{
 	{Text: templates.ClusterIdentity},
 	{
 		Text: templates.OCIManagedCluster,
-		LockedFields: map[string]bool{
-			"networkSpec": true,
+		OwnershipRules: []OwnershipRule{
+			{
+				Path:       []string{"spec", "networkSpec"},
+				CreateOnly: true,
+			},
+			{
+				// upgrading the driver doesn't retag the cluster's resources
+				Path:       []string{"spec", "freeformTags", variables.DriverVersionTag},
+				CreateOnly: true,
+			},
 		},
 	},
 }
 
 var CAPICluster = Object{Text: templates.Cluster}
+
+var ClusterAutoscaler = Object{Text: templates.ClusterAutoscaler}
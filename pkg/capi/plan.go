@@ -0,0 +1,239 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sort"
+	"strings"
+)
+
+const (
+	PlanCreate = "create"
+	PlanUpdate = "update"
+	PlanDelete = "delete"
+)
+
+// Plan is the set of changes an update would make to the cluster's objects
+type Plan struct {
+	Changes []PlannedChange `json:"changes"`
+}
+
+// PlannedChange is a change to a single object
+type PlannedChange struct {
+	Action    string        `json:"action"`
+	Kind      string        `json:"kind"`
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Fields    []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a change to a single field of an object. Old is empty for fields that are added.
+type FieldChange struct {
+	Path string `json:"path"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new"`
+}
+
+func (p *Plan) String() string {
+	if len(p.Changes) < 1 {
+		return "no changes"
+	}
+	sb := strings.Builder{}
+	for i, change := range p.Changes {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s/%s", change.Action, change.Kind, change.Namespace, change.Name))
+		for _, field := range change.Fields {
+			if field.Old == "" {
+				sb.WriteString(fmt.Sprintf("\n  %s: %s", field.Path, field.New))
+			} else {
+				sb.WriteString(fmt.Sprintf("\n  %s: %s -> %s", field.Path, field.Old, field.New))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// PlanUpdate renders the cluster's objects, and diffs them against the live objects without applying anything.
+// The plan includes the MachinePools that the update would delete.
+func (c *CAPIClient) PlanUpdate(ctx context.Context, di dynamic.Interface, v *variables.Variables) (*Plan, error) {
+	plan := &Plan{}
+	objects := append(append(append([]object.Object{}, object.ControlPlane...), object.Workers...), object.UpdateObjects()...)
+	if v.IsAutoscaled() {
+		objects = append(objects, object.ClusterAutoscaler)
+	}
+	for _, o := range objects {
+		if err := plan.addObjectChanges(ctx, di, o, v); err != nil {
+			return nil, err
+		}
+	}
+	if !v.IsAutoscaled() {
+		if err := plan.addDeletedObjects(ctx, di, object.ClusterAutoscaler, v); err != nil {
+			return nil, err
+		}
+	}
+	if err := plan.addDeletedMachinePools(ctx, di, v); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (p *Plan) addObjectChanges(ctx context.Context, di dynamic.Interface, o object.Object, v *variables.Variables) error {
+	us, err := object.LoadTextTemplate(o, *v)
+	if err != nil {
+		return err
+	}
+	for idx := range us {
+		u := &us[idx]
+		live, err := getLiveObject(ctx, di, u)
+		if err != nil {
+			return err
+		}
+		if live == nil {
+			p.add(PlanCreate, u, nil)
+			continue
+		}
+		if err := applyOwnershipRules(u, live, o.OwnershipRules); err != nil {
+			return err
+		}
+		if fields := diffFields(nil, u.Object, live.Object); len(fields) > 0 {
+			p.add(PlanUpdate, u, fields)
+		}
+	}
+	return nil
+}
+
+// addDeletedObjects adds the rendered objects that exist, and would be deleted
+func (p *Plan) addDeletedObjects(ctx context.Context, di dynamic.Interface, o object.Object, v *variables.Variables) error {
+	us, err := object.LoadTextTemplate(o, *v)
+	if err != nil {
+		return err
+	}
+	for idx := range us {
+		live, err := getLiveObject(ctx, di, &us[idx])
+		if err != nil {
+			return err
+		}
+		if live != nil {
+			p.add(PlanDelete, live, nil)
+		}
+	}
+	return nil
+}
+
+// addDeletedMachinePools adds the MachinePools, and their OCIManagedMachinePools, that are no longer in the cluster's node pools
+func (p *Plan) addDeletedMachinePools(ctx context.Context, di dynamic.Interface, v *variables.Variables) error {
+	mps, err := di.Resource(gvr.MachinePool).Namespace(v.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for idx := range mps.Items {
+		mp := &mps.Items[idx]
+		if findNodePool(v, mp.GetName()) {
+			continue
+		}
+		p.add(PlanDelete, mp, nil)
+		poolName, _, _ := unstructured.NestedString(mp.Object, "spec", "template", "spec", "infrastructureRef", "name")
+		if poolName == "" {
+			continue
+		}
+		ociMachinePool, err := di.Resource(gvr.OCIMachinePools).Namespace(v.Namespace).Get(ctx, poolName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			p.add(PlanDelete, ociMachinePool, nil)
+		}
+	}
+	return nil
+}
+
+func (p *Plan) add(action string, u *unstructured.Unstructured, fields []FieldChange) {
+	p.Changes = append(p.Changes, PlannedChange{
+		Action:    action,
+		Kind:      u.GetKind(),
+		Namespace: object.DefaultingNamespace(u),
+		Name:      u.GetName(),
+		Fields:    fields,
+	})
+}
+
+func getLiveObject(ctx context.Context, di dynamic.Interface, u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	groupVersionResource := object.GVR(u)
+	live, err := di.Resource(groupVersionResource).Namespace(object.DefaultingNamespace(u)).Get(ctx, u.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get failed %s/%s/%s: %v", groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource, err)
+	}
+	return live, nil
+}
+
+func findNodePool(v *variables.Variables, name string) bool {
+	for _, np := range v.NodePools {
+		if np.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// diffFields compares the fields set by the desired object to the live object.
+// Fields only set on the live object are owned by other managers, and are not part of the diff.
+func diffFields(path []string, desired, live map[string]interface{}) []FieldChange {
+	var keys []string
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var changes []FieldChange
+	for _, k := range keys {
+		fieldPath := append(append([]string{}, path...), k)
+		desiredValue := desired[k]
+		liveValue, found := live[k]
+		if !found {
+			changes = append(changes, FieldChange{
+				Path: strings.Join(fieldPath, "."),
+				New:  fieldString(desiredValue),
+			})
+			continue
+		}
+		desiredMap, isDesiredMap := desiredValue.(map[string]interface{})
+		liveMap, isLiveMap := liveValue.(map[string]interface{})
+		if isDesiredMap && isLiveMap {
+			changes = append(changes, diffFields(fieldPath, desiredMap, liveMap)...)
+			continue
+		}
+		if desiredString, liveString := fieldString(desiredValue), fieldString(liveValue); desiredString != liveString {
+			changes = append(changes, FieldChange{
+				Path: strings.Join(fieldPath, "."),
+				Old:  liveString,
+				New:  desiredString,
+			})
+		}
+	}
+	return changes
+}
+
+// fieldString formats a field value as JSON, so equal values compare equal regardless of their type
+func fieldString(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(b)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func TestPlanUpdate(t *testing.T) {
+	ctx := context.TODO()
+	v := *testVariables
+	v.KubernetesVersion = "v1.26.2"
+	v.NodePools = []variables.NodePool{
+		{Name: "np-1", Replicas: 1},
+		{Name: "np-2", Replicas: 1},
+	}
+	// the live cluster was created with np-1 and np-3
+	live := v
+	live.NodePools = []variables.NodePool{
+		{Name: "np-1", Replicas: 1},
+		{Name: "np-3", Replicas: 1},
+	}
+	var liveObjects []runtime.Object
+	for _, o := range append(append(append([]object.Object{}, object.ControlPlane...), object.Workers...), object.UpdateObjects()...) {
+		us, err := object.LoadTextTemplate(o, live)
+		assert.NoError(t, err)
+		for idx := range us {
+			// the live cluster was created by an older driver
+			if us[idx].GetKind() == "OCIManagedCluster" {
+				assert.NoError(t, unstructured.SetNestedField(us[idx].Object, "v0.1.0", "spec", "freeformTags", variables.DriverVersionTag))
+			}
+			liveObjects = append(liveObjects, &us[idx])
+		}
+	}
+	di := createTestDI(liveObjects...)
+
+	// upgrade the live cluster, cycling np-1's nodes faster
+	v.KubernetesVersion = "v1.27.2"
+	v.NodePools[0].MaxSurge = "50%"
+	plan, err := testCAPIClient.PlanUpdate(ctx, di, &v)
+	assert.NoError(t, err)
+
+	changes := map[string]PlannedChange{}
+	for _, change := range plan.Changes {
+		changes[change.Action+" "+change.Kind+" "+change.Name] = change
+	}
+	assert.Contains(t, changes, "create MachinePool np-2")
+	assert.Contains(t, changes, "create OCIManagedMachinePool np-2")
+	assert.Contains(t, changes, "delete MachinePool np-3")
+	assert.Contains(t, changes, "delete OCIManagedMachinePool np-3")
+	assert.Equal(t, []FieldChange{
+		{
+			Path: "spec.version",
+			Old:  "\"v1.26.2\"",
+			New:  "\"v1.27.2\"",
+		},
+	}, changes["update OCIManagedControlPlane "+testName].Fields)
+	assert.Contains(t, changes, "update MachinePool np-1")
+	assert.Contains(t, changes["update OCIManagedMachinePool np-1"].Fields, FieldChange{
+		Path: "spec.nodePoolCyclingDetails.maximumSurge",
+		Old:  "\"1\"",
+		New:  "\"50%\"",
+	})
+	assert.NotContains(t, changes, "update Cluster "+testName)
+	assert.NotContains(t, changes, "update OCIManagedCluster "+testName)
+	assert.Contains(t, plan.String(), "spec.version: \"v1.26.2\" -> \"v1.27.2\"")
+
+	// nothing was applied
+	for _, action := range di.Actions() {
+		assert.Contains(t, []string{"get", "list"}, action.GetVerb())
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	desired := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"version":  "v1.27.2",
+			"labels":   []interface{}{"a"},
+		},
+	}
+	live := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas":  float64(3),
+			"version":   "v1.26.2",
+			"defaulted": true,
+		},
+	}
+	assert.Equal(t, []FieldChange{
+		{Path: "spec.labels", New: "[\"a\"]"},
+		{Path: "spec.version", Old: "\"v1.26.2\"", New: "\"v1.27.2\""},
+	}, diffFields(nil, desired, live))
+	assert.Equal(t, "no changes", (&Plan{}).String())
+}
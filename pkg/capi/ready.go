@@ -6,71 +6,209 @@ package capi
 import (
 	"context"
 	"errors"
-	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"fmt"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
+	"strings"
 )
 
+const (
+	ComponentCluster        = "cluster"
+	ComponentControlPlane   = "control plane"
+	ComponentMachinePool    = "machine pool"
+	ComponentOCIMachinePool = "OCI machine pool"
+
+	reasonNotFound             = "NotFound"
+	reasonNotReady             = "NotReady"
+	reasonWaitingForReplicas   = "WaitingForReplicas"
+	conditionSeverityError     = "Error"
+	conditionStatusTrue        = "True"
+	readyConditionType         = "Ready"
+	defaultMachinePoolReplicas = 1
+)
+
+// Readiness is the result of evaluating a cluster's readiness. If the cluster is not ready,
+// Readiness describes the first component that is not ready, and why.
+type Readiness struct {
+	Ready     bool
+	Component string
+	Name      string
+	Reason    string
+	Message   string
+	// Degraded is true if the component reported an error, rather than still progressing
+	Degraded bool
+}
+
+func (r *Readiness) String() string {
+	if r.Ready {
+		return "cluster is ready"
+	}
+	msg := fmt.Sprintf("%s %s is not ready", r.Component, r.Name)
+	if r.Degraded {
+		msg = fmt.Sprintf("%s %s is degraded", r.Component, r.Name)
+	}
+	if r.Reason != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, r.Reason)
+	}
+	if r.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, r.Message)
+	}
+	return msg
+}
+
 func IsCAPIClusterReady(ctx context.Context, client dynamic.Interface, state *variables.Variables, plog *provisioning.Logger) error {
 	cluster, err := client.Resource(gvr.Cluster).Namespace(state.Namespace).Get(ctx, state.Name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
-	if isClusterReady(cluster, state) {
-		poolsReady := true
-		if len(state.NodePools) > 0 {
-			poolsReady, err = areMachinePoolsReady(ctx, client, state)
-			if err != nil {
-				_ = plog.ClusterStatus(cluster)
-				return errors.New("Waiting for nodes to be ready")
-			}
-		}
-		if poolsReady {
-			return nil
+	objects, err := getClusterObjects(ctx, client, state, cluster)
+	if err != nil {
+		_ = plog.ClusterStatus(cluster)
+		return errors.New("Waiting for nodes to be ready")
+	}
+	readiness := EvaluateReadiness(state, objects)
+	if readiness.Ready {
+		if addons := describeAddons(objects.ControlPlane); len(addons) > 0 {
+			_ = plog.Infof("%s", strings.Join(addons, ", "))
 		}
+		return nil
 	}
 	_ = plog.ClusterStatus(cluster)
-	return errors.New("Waiting for cluster to be ready")
+	return fmt.Errorf("Waiting for cluster to be ready: %s", readiness)
+}
+
+// ClusterProvisioned is true once the cluster and its control plane are ready
+func ClusterProvisioned(state *variables.Variables, objects *ClusterObjects) bool {
+	return evaluateControlPlaneReadiness(state, objects).Ready
 }
 
-func isClusterReady(cluster *unstructured.Unstructured, state *variables.Variables) bool {
-	switch state.KubernetesVersion {
-	case "v1.25.4":
-		controlPlaneReady, err := object.NestedField(cluster.Object, "status", "controlPlaneReady")
-		if err != nil {
-			return false
+// ClusterReady is true once the cluster, its control plane and all of its node pools are ready
+func ClusterReady(state *variables.Variables, objects *ClusterObjects) bool {
+	return EvaluateReadiness(state, objects).Ready
+}
+
+// EvaluateReadiness evaluates the readiness of the cluster, its control plane and each of its node pools
+// using the CAPI Ready conditions. Node pools must also have all of their desired replicas ready.
+func EvaluateReadiness(state *variables.Variables, objects *ClusterObjects) *Readiness {
+	if r := evaluateControlPlaneReadiness(state, objects); !r.Ready {
+		return r
+	}
+	for _, np := range state.NodePools {
+		mp := findObject(objects.MachinePools, np.Name)
+		if r := evaluateMachinePool(np.Name, mp); !r.Ready {
+			return r
 		}
-		controlPlaneReadyBool, ok := controlPlaneReady.(bool)
-		if !ok || !controlPlaneReadyBool {
-			return false
+		ocimp := findObject(objects.OCIMachinePools, np.Name)
+		if r := evaluateObject(ComponentOCIMachinePool, np.Name, ocimp); !r.Ready {
+			return r
 		}
 	}
-	infrastructureReady, err := object.NestedField(cluster.Object, "status", "infrastructureReady")
-	if err != nil {
-		return false
+	return &Readiness{Ready: true}
+}
+
+func evaluateControlPlaneReadiness(state *variables.Variables, objects *ClusterObjects) *Readiness {
+	if r := evaluateObject(ComponentCluster, state.Name, objects.Cluster); !r.Ready {
+		return r
+	}
+	return evaluateObject(ComponentControlPlane, objects.controlPlaneName(state), objects.ControlPlane)
+}
+
+// evaluateMachinePool checks the MachinePool is ready, and has all of its desired replicas ready
+func evaluateMachinePool(name string, mp *unstructured.Unstructured) *Readiness {
+	if r := evaluateObject(ComponentMachinePool, name, mp); !r.Ready {
+		return r
+	}
+	desired, found, _ := unstructured.NestedInt64(mp.Object, "spec", "replicas")
+	if !found {
+		desired = defaultMachinePoolReplicas
 	}
-	infrastructureReadyBool, ok := infrastructureReady.(bool)
-	if !ok || !infrastructureReadyBool {
-		return false
+	ready, _, _ := unstructured.NestedInt64(mp.Object, "status", "readyReplicas")
+	if ready < desired {
+		return &Readiness{
+			Component: ComponentMachinePool,
+			Name:      name,
+			Reason:    reasonWaitingForReplicas,
+			Message:   fmt.Sprintf("%d of %d replicas ready", ready, desired),
+		}
 	}
+	return &Readiness{Ready: true}
+}
 
-	phase, err := object.NestedField(cluster.Object, "status", "phase")
-	if err != nil {
-		return false
+// evaluateObject checks an object's Ready condition.
+// Objects that do not report conditions fall back to their status.ready field.
+func evaluateObject(component, name string, u *unstructured.Unstructured) *Readiness {
+	if u == nil {
+		return &Readiness{
+			Component: component,
+			Name:      name,
+			Reason:    reasonNotFound,
+		}
+	}
+	if condition := readyCondition(u); condition != nil {
+		if condition["status"] == conditionStatusTrue {
+			return &Readiness{Ready: true}
+		}
+		reason, _ := condition["reason"].(string)
+		message, _ := condition["message"].(string)
+		if reason == "" {
+			reason = reasonNotReady
+		}
+		return &Readiness{
+			Component: component,
+			Name:      name,
+			Reason:    reason,
+			Message:   message,
+			Degraded:  condition["severity"] == conditionSeverityError,
+		}
+	}
+	if ready, _, _ := unstructured.NestedBool(u.Object, "status", "ready"); ready {
+		return &Readiness{Ready: true}
+	}
+	return &Readiness{
+		Component: component,
+		Name:      name,
+		Reason:    reasonNotReady,
+	}
+}
+
+func findObject(us []*unstructured.Unstructured, name string) *unstructured.Unstructured {
+	for _, u := range us {
+		if u.GetName() == name {
+			return u
+		}
+	}
+	return nil
+}
+
+// getClusterObjects fetches the cluster's CAPI objects
+func getClusterObjects(ctx context.Context, client dynamic.Interface, state *variables.Variables, cluster *unstructured.Unstructured) (*ClusterObjects, error) {
+	objects := &ClusterObjects{
+		Cluster: cluster,
+	}
+	controlPlane, err := client.Resource(gvr.OCIControlPlane).Namespace(state.Namespace).Get(ctx, objects.controlPlaneName(state), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		objects.ControlPlane = controlPlane
+	}
+	if objects.MachinePools, err = listClusterObjects(ctx, client, state, gvr.MachinePool); err != nil {
+		return nil, err
 	}
-	phaseString, ok := phase.(string)
-	if !ok || phaseString != clusterPhaseProvisioned {
-		return false
+	if objects.OCIMachinePools, err = listClusterObjects(ctx, client, state, gvr.OCIMachinePools); err != nil {
+		return nil, err
 	}
-	return true
+	return objects, nil
 }
 
-func areMachinePoolsReady(ctx context.Context, client dynamic.Interface, state *variables.Variables) (bool, error) {
-	poolList, err := client.Resource(gvr.MachinePool).List(ctx, metav1.ListOptions{
+func listClusterObjects(ctx context.Context, client dynamic.Interface, state *variables.Variables, resource schema.GroupVersionResource) ([]*unstructured.Unstructured, error) {
+	list, err := client.Resource(resource).Namespace(state.Namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{
 			MatchLabels: map[string]string{
 				"cluster.x-k8s.io/cluster-name": state.Name,
@@ -78,25 +216,11 @@ func areMachinePoolsReady(ctx context.Context, client dynamic.Interface, state *
 		}),
 	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-
-	if poolList == nil || len(poolList.Items) < 1 {
-		return false, nil
-	}
-
-	for _, pool := range poolList.Items {
-		phase, err := object.NestedField(pool.Object, "status", "phase")
-		if err != nil {
-			return false, nil
-		}
-		phaseString, ok := phase.(string)
-		if !ok {
-			return false, nil
-		}
-		if phaseString != machinePoolPhaseRunning {
-			return false, nil
-		}
+	var us []*unstructured.Unstructured
+	for idx := range list.Items {
+		us = append(us, &list.Items[idx])
 	}
-	return true, nil
+	return us, nil
 }
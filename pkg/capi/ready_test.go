@@ -0,0 +1,165 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	fakelogger "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning/fake"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func testPool(name string, replicas, readyReplicas int64, conditions ...interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+		"status": map[string]interface{}{
+			"readyReplicas": readyReplicas,
+			"conditions":    conditions,
+		},
+	}}
+	u.SetName(name)
+	return u
+}
+
+func TestEvaluateReadiness(t *testing.T) {
+	v := *testVariables
+	v.KubernetesVersion = "v1.26.2"
+	v.NodePools = []variables.NodePool{{Name: "np-1", Replicas: 2}}
+	cluster := createTestCluster(&v, true, true, clusterPhaseProvisioned)
+	controlPlane := createTestControlPlane(&v, true)
+	ociPool := testPool("np-1", 0, 0, testReadyCondition(true))
+
+	var tests = []struct {
+		name      string
+		objects   *ClusterObjects
+		ready     bool
+		component string
+		reason    string
+		degraded  bool
+	}{
+		{
+			"ready",
+			&ClusterObjects{
+				Cluster:         cluster,
+				ControlPlane:    controlPlane,
+				MachinePools:    []*unstructured.Unstructured{testPool("np-1", 2, 2, testReadyCondition(true))},
+				OCIMachinePools: []*unstructured.Unstructured{ociPool},
+			},
+			true,
+			"",
+			"",
+			false,
+		},
+		{
+			"cluster not found",
+			&ClusterObjects{},
+			false,
+			ComponentCluster,
+			reasonNotFound,
+			false,
+		},
+		{
+			"cluster not ready",
+			&ClusterObjects{
+				Cluster:      createTestCluster(&v, false, true, "Provisioning"),
+				ControlPlane: controlPlane,
+			},
+			false,
+			ComponentCluster,
+			"WaitingForInfrastructure",
+			false,
+		},
+		{
+			"control plane not ready",
+			&ClusterObjects{
+				Cluster:      cluster,
+				ControlPlane: createTestControlPlane(&v, false),
+			},
+			false,
+			ComponentControlPlane,
+			"WaitingForInfrastructure",
+			false,
+		},
+		{
+			"machine pool degraded",
+			&ClusterObjects{
+				Cluster:      cluster,
+				ControlPlane: controlPlane,
+				MachinePools: []*unstructured.Unstructured{testPool("np-1", 2, 0, map[string]interface{}{
+					"type":     "Ready",
+					"status":   "False",
+					"reason":   "InfrastructureFailed",
+					"severity": "Error",
+				})},
+				OCIMachinePools: []*unstructured.Unstructured{ociPool},
+			},
+			false,
+			ComponentMachinePool,
+			"InfrastructureFailed",
+			true,
+		},
+		{
+			"machine pool replicas not ready",
+			&ClusterObjects{
+				Cluster:         cluster,
+				ControlPlane:    controlPlane,
+				MachinePools:    []*unstructured.Unstructured{testPool("np-1", 2, 1, testReadyCondition(true))},
+				OCIMachinePools: []*unstructured.Unstructured{ociPool},
+			},
+			false,
+			ComponentMachinePool,
+			reasonWaitingForReplicas,
+			false,
+		},
+		{
+			"OCI machine pool not found",
+			&ClusterObjects{
+				Cluster:      cluster,
+				ControlPlane: controlPlane,
+				MachinePools: []*unstructured.Unstructured{testPool("np-1", 2, 2, testReadyCondition(true))},
+			},
+			false,
+			ComponentOCIMachinePool,
+			reasonNotFound,
+			false,
+		},
+		{
+			"objects without conditions use status.ready",
+			&ClusterObjects{
+				Cluster:         cluster,
+				ControlPlane:    controlPlane,
+				MachinePools:    []*unstructured.Unstructured{testPool("np-1", 2, 2, testReadyCondition(true))},
+				OCIMachinePools: []*unstructured.Unstructured{{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "np-1"}, "status": map[string]interface{}{"ready": true}}}},
+			},
+			true,
+			"",
+			"",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := EvaluateReadiness(&v, tt.objects)
+			assert.Equal(t, tt.ready, r.Ready)
+			assert.Equal(t, tt.component, r.Component)
+			assert.Equal(t, tt.reason, r.Reason)
+			assert.Equal(t, tt.degraded, r.Degraded)
+		})
+	}
+}
+
+func TestIsCAPIClusterReady(t *testing.T) {
+	ctx := context.TODO()
+	di := createTestDI(createTestCluster(testVariables, true, true, clusterPhaseProvisioned))
+	err := IsCAPIClusterReady(ctx, di, testVariables, fakelogger.NewLogger())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "control plane test is not ready (NotFound)")
+
+	assert.NoError(t, IsCAPIClusterReady(ctx, createTestDIWithClusterAndMachine(), testVariables, fakelogger.NewLogger()))
+}
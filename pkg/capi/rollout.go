@@ -0,0 +1,283 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"fmt"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"strings"
+)
+
+const (
+	// rolloutConfigMapName stores the node pools a rollout has yet to upgrade, so a retried update resumes at the right pool
+	rolloutConfigMapName = "node-pool-rollout"
+	rolloutPendingField  = "pending"
+
+	machinePoolPhaseRunning = "Running"
+
+	reasonNodeVersion  = "WaitingForNodeVersion"
+	reasonNodeNotReady = "NodeNotReady"
+)
+
+// ManagedInterface creates a client for the managed cluster. Rollouts use it to check the cluster's nodes.
+type ManagedInterface func(ctx context.Context) (kubernetes.Interface, error)
+
+// rolloutNodePools upgrades the node pools pending a rollout one at a time. Each pool must pass a health gate before the next pool starts:
+// the pool is Running, its nodes report the pool's Kubernetes version, and none of the cluster's nodes are NotReady.
+// Paused rollouts leave the pending pools at their current version.
+func (c *CAPIClient) rolloutNodePools(ctx context.Context, ki kubernetes.Interface, di dynamic.Interface, managed ManagedInterface, v *variables.Variables, pending []string) error {
+	if len(pending) == 0 {
+		return nil
+	}
+	if v.PauseNodePoolRollout {
+		_ = c.plog.Infof("Node pool rollout is paused, node pools %s have not been upgraded", strings.Join(pending, ", "))
+		return nil
+	}
+	managedKI, err := managed(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create client for managed cluster %s: %v", v.Name, err)
+	}
+	for len(pending) > 0 {
+		np, ok := findNodePoolByName(v, pending[0])
+		if !ok {
+			pending = pending[1:]
+			continue
+		}
+		version := nodePoolVersion(v, np)
+		_ = c.plog.Infof("Upgrading node pool %s to %s, %d node pools remaining", np.Name, version, len(pending)-1)
+		pool := *v
+		pool.NodePools = []variables.NodePool{np}
+		if _, err := createOrUpdateObjects(ctx, di, object.Workers, &pool); err != nil {
+			return fmt.Errorf("error upgrading node pool %s: %v", np.Name, err)
+		}
+		if err := c.waitForNodePool(ctx, di, managedKI, v, np.Name, version); err != nil {
+			return err
+		}
+		pending = pending[1:]
+		if err := storeRollout(ctx, ki, v, pending); err != nil {
+			return err
+		}
+		_ = c.plog.Infof("Upgraded node pool %s to %s", np.Name, version)
+	}
+	return nil
+}
+
+// waitForNodePool waits for a node pool to pass the rollout's health gate
+func (c *CAPIClient) waitForNodePool(ctx context.Context, di dynamic.Interface, managedKI kubernetes.Interface, v *variables.Variables, name, version string) error {
+	var lastState string
+	err := wait.PollImmediateWithContext(ctx, c.rolloutPollingInterval, v.RolloutTimeout(), func(ctx context.Context) (bool, error) {
+		mp, err := di.Resource(gvr.MachinePool).Namespace(v.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		if apierrors.IsNotFound(err) {
+			mp = nil
+		}
+		nodes, err := managedKI.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			// the managed cluster's API server may be briefly unavailable while nodes are cycled
+			_ = c.plog.Infof("Failed to list nodes of cluster %s: %v", v.Name, err)
+			return false, nil
+		}
+		readiness := evaluateNodePoolRollout(name, version, mp, nodes.Items)
+		if state := readiness.String(); state != lastState {
+			_ = c.plog.Infof("Node pool %s upgrade: %s", name, state)
+			lastState = state
+		}
+		return readiness.Ready, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out waiting for node pool %s to be upgraded to %s: %s", name, version, lastState)
+	}
+	return err
+}
+
+// evaluateNodePoolRollout evaluates the rollout's health gate for a node pool
+func evaluateNodePoolRollout(name, version string, mp *unstructured.Unstructured, nodes []corev1.Node) *Readiness {
+	if r := evaluateMachinePool(name, mp); !r.Ready {
+		return r
+	}
+	if phase, _, _ := unstructured.NestedString(mp.Object, "status", "phase"); phase != machinePoolPhaseRunning {
+		return &Readiness{
+			Component: ComponentMachinePool,
+			Name:      name,
+			Reason:    reasonNotReady,
+			Message:   fmt.Sprintf("phase is %s", phase),
+		}
+	}
+	poolNodes := map[string]bool{}
+	nodeRefs, _, _ := unstructured.NestedSlice(mp.Object, "status", "nodeRefs")
+	for _, ref := range nodeRefs {
+		if r, ok := ref.(map[string]interface{}); ok {
+			if nodeName, ok := r["name"].(string); ok {
+				poolNodes[nodeName] = true
+			}
+		}
+	}
+	for _, node := range nodes {
+		if !isNodeReady(node) {
+			return &Readiness{
+				Component: ComponentMachinePool,
+				Name:      name,
+				Reason:    reasonNodeNotReady,
+				Message:   fmt.Sprintf("node %s is not ready", node.Name),
+			}
+		}
+		if kubeletVersion := node.Status.NodeInfo.KubeletVersion; poolNodes[node.Name] && !sameVersion(kubeletVersion, version) {
+			return &Readiness{
+				Component: ComponentMachinePool,
+				Name:      name,
+				Reason:    reasonNodeVersion,
+				Message:   fmt.Sprintf("node %s is running %s", node.Name, kubeletVersion),
+			}
+		}
+	}
+	return &Readiness{Ready: true}
+}
+
+func isNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+// pendingNodePools are the node pools a rollout has yet to upgrade, in the cluster's node pool order.
+// Pools are pending if an earlier rollout didn't upgrade them, or if their live version differs from their desired version.
+func pendingNodePools(ctx context.Context, ki kubernetes.Interface, di dynamic.Interface, v *variables.Variables) ([]string, error) {
+	stored, err := loadRollout(ctx, ki, v)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, np := range v.NodePools {
+		liveVersion, err := liveNodePoolVersion(ctx, di, v, np.Name)
+		if err != nil {
+			return nil, err
+		}
+		if containsString(stored, np.Name) || (liveVersion != "" && !sameVersion(liveVersion, nodePoolVersion(v, np))) {
+			pending = append(pending, np.Name)
+		}
+	}
+	return pending, nil
+}
+
+// pinNodePoolVersions keeps the pending node pools at their live version, so they are only upgraded by the rollout
+func pinNodePoolVersions(ctx context.Context, di dynamic.Interface, v *variables.Variables, pending []string) (*variables.Variables, error) {
+	pinned := *v
+	pinned.NodePools = append([]variables.NodePool{}, v.NodePools...)
+	for i, np := range pinned.NodePools {
+		if !containsString(pending, np.Name) {
+			continue
+		}
+		liveVersion, err := liveNodePoolVersion(ctx, di, v, np.Name)
+		if err != nil {
+			return nil, err
+		}
+		if liveVersion != "" {
+			pinned.NodePools[i].Version = liveVersion
+		}
+	}
+	return &pinned, nil
+}
+
+// liveNodePoolVersion is the Kubernetes version of a node pool's MachinePool, or empty if the pool doesn't exist
+func liveNodePoolVersion(ctx context.Context, di dynamic.Interface, v *variables.Variables, name string) (string, error) {
+	mp, err := di.Resource(gvr.MachinePool).Namespace(v.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	version, _, _ := unstructured.NestedString(mp.Object, "spec", "template", "spec", "version")
+	return version, nil
+}
+
+// nodePoolVersion is a node pool's desired Kubernetes version
+func nodePoolVersion(v *variables.Variables, np variables.NodePool) string {
+	if np.Version != "" {
+		return np.Version
+	}
+	return v.KubernetesVersion
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func findNodePoolByName(v *variables.Variables, name string) (variables.NodePool, bool) {
+	for _, np := range v.NodePools {
+		if np.Name == name {
+			return np, true
+		}
+	}
+	return variables.NodePool{}, false
+}
+
+func loadRollout(ctx context.Context, ki kubernetes.Interface, v *variables.Variables) ([]string, error) {
+	cm, err := ki.CoreV1().ConfigMaps(v.Namespace).Get(ctx, rolloutConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load node pool rollout: %v", err)
+	}
+	if pending := cm.Data[rolloutPendingField]; pending != "" {
+		return strings.Split(pending, ","), nil
+	}
+	return nil, nil
+}
+
+// storeRollout stores the node pools a rollout has yet to upgrade. Finished rollouts are removed.
+func storeRollout(ctx context.Context, ki kubernetes.Interface, v *variables.Variables, pending []string) error {
+	if len(pending) == 0 {
+		err := ki.CoreV1().ConfigMaps(v.Namespace).Delete(ctx, rolloutConfigMapName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to remove node pool rollout: %v", err)
+		}
+		return nil
+	}
+	data := map[string]string{
+		rolloutPendingField: strings.Join(pending, ","),
+	}
+	cm, err := ki.CoreV1().ConfigMaps(v.Namespace).Get(ctx, rolloutConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = ki.CoreV1().ConfigMaps(v.Namespace).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      rolloutConfigMapName,
+				Namespace: v.Namespace,
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+	} else if err == nil {
+		cm.Data = data
+		_, err = ki.CoreV1().ConfigMaps(v.Namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store node pool rollout: %v", err)
+	}
+	return nil
+}
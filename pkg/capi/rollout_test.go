@@ -0,0 +1,287 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	fakelogger "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning/fake"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	fake2 "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+	"time"
+)
+
+func TestEvaluateNodePoolRollout(t *testing.T) {
+	var tests = []struct {
+		name   string
+		mp     *unstructured.Unstructured
+		nodes  []corev1.Node
+		ready  bool
+		reason string
+	}{
+		{
+			"missing node pool",
+			nil,
+			nil,
+			false,
+			reasonNotFound,
+		},
+		{
+			"node pool scaling",
+			createRolloutMachinePool("np-1", "ScalingUp", "node-1"),
+			[]corev1.Node{createRolloutNode("node-1", "v1.27.2", true)},
+			false,
+			reasonNotReady,
+		},
+		{
+			"node on the old version",
+			createRolloutMachinePool("np-1", machinePoolPhaseRunning, "node-1"),
+			[]corev1.Node{createRolloutNode("node-1", "v1.26.2", true)},
+			false,
+			reasonNodeVersion,
+		},
+		{
+			"NotReady node in another pool",
+			createRolloutMachinePool("np-1", machinePoolPhaseRunning, "node-1"),
+			[]corev1.Node{createRolloutNode("node-1", "v1.27.2", true), createRolloutNode("node-2", "v1.26.2", false)},
+			false,
+			reasonNodeNotReady,
+		},
+		{
+			"upgraded",
+			createRolloutMachinePool("np-1", machinePoolPhaseRunning, "node-1"),
+			[]corev1.Node{createRolloutNode("node-1", "1.27.2", true), createRolloutNode("node-2", "v1.26.2", true)},
+			true,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := evaluateNodePoolRollout("np-1", "v1.27.2", tt.mp, tt.nodes)
+			assert.Equal(t, tt.ready, r.Ready)
+			assert.Equal(t, tt.reason, r.Reason)
+		})
+	}
+}
+
+func TestRolloutNodePools(t *testing.T) {
+	ctx := context.TODO()
+	c := NewCAPIClient(fakelogger.NewLogger())
+	c.rolloutPollingInterval = time.Millisecond
+
+	v := *testVariables
+	v.NodePoolRolloutTimeout = "100ms"
+	v.KubernetesVersion = "v1.26.2"
+	v.NodePools = []variables.NodePool{
+		{Name: "np-1", Replicas: 1},
+		{Name: "np-2", Replicas: 1},
+	}
+	di := createRolloutDI(t, v)
+	ki := fake.NewSimpleClientset()
+	nodes := fake.NewSimpleClientset(
+		createRolloutNodeObject("np-1-node", "v1.27.2"),
+		createRolloutNodeObject("np-2-node", "v1.27.2"),
+	)
+	managed := func(_ context.Context) (kubernetes.Interface, error) {
+		return nodes, nil
+	}
+
+	// upgrade the cluster, both pools are pending
+	v.KubernetesVersion = "v1.27.2"
+	pending, err := pendingNodePools(ctx, ki, di, &v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"np-1", "np-2"}, pending)
+	assert.NoError(t, storeRollout(ctx, ki, &v, pending))
+
+	// pending pools are pinned to their live version
+	pinned, err := pinNodePoolVersions(ctx, di, &v, pending)
+	assert.NoError(t, err)
+	for _, np := range pinned.NodePools {
+		assert.Equal(t, "v1.26.2", np.Version)
+	}
+	assert.Empty(t, v.NodePools[0].Version)
+
+	// paused rollouts don't upgrade any pools
+	v.PauseNodePoolRollout = true
+	assert.NoError(t, c.rolloutNodePools(ctx, ki, di, managed, &v, pending))
+	assertLiveNodePoolVersion(t, di, &v, "np-1", "v1.26.2")
+	stored, err := loadRollout(ctx, ki, &v)
+	assert.NoError(t, err)
+	assert.Equal(t, pending, stored)
+
+	// np-2's nodes aren't upgraded, so the rollout stops at np-2
+	v.PauseNodePoolRollout = false
+	_, err = nodes.CoreV1().Nodes().Update(ctx, createRolloutNodeObject("np-2-node", "v1.26.2"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	err = c.rolloutNodePools(ctx, ki, di, managed, &v, pending)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "np-2-node is running v1.26.2")
+	assertLiveNodePoolVersion(t, di, &v, "np-1", "v1.27.2")
+
+	// a retried update resumes at np-2, even though its version has been applied
+	pending, err = pendingNodePools(ctx, ki, di, &v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"np-2"}, pending)
+	_, err = nodes.CoreV1().Nodes().Update(ctx, createRolloutNodeObject("np-2-node", "v1.27.2"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, c.rolloutNodePools(ctx, ki, di, managed, &v, pending))
+	assertLiveNodePoolVersion(t, di, &v, "np-2", "v1.27.2")
+
+	// finished rollouts are removed
+	_, err = ki.CoreV1().ConfigMaps(v.Namespace).Get(ctx, rolloutConfigMapName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestResumeRolloutNodePools(t *testing.T) {
+	ctx := context.TODO()
+	c := NewCAPIClient(fakelogger.NewLogger())
+	c.rolloutPollingInterval = time.Millisecond
+
+	// an earlier update upgraded np-1, and stopped before np-2 and np-3
+	live := *testVariables
+	live.KubernetesVersion = "v1.26.2"
+	live.NodePools = []variables.NodePool{
+		{Name: "np-1", Replicas: 1, Version: "v1.27.2"},
+		{Name: "np-2", Replicas: 1},
+		{Name: "np-3", Replicas: 1},
+	}
+	di := createRolloutDI(t, live)
+	ki := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rolloutConfigMapName,
+			Namespace: live.Namespace,
+		},
+		Data: map[string]string{
+			rolloutPendingField: "np-2,np-3",
+		},
+	})
+	nodes := fake.NewSimpleClientset(
+		createRolloutNodeObject("np-1-node", "v1.27.2"),
+		createRolloutNodeObject("np-2-node", "v1.27.2"),
+		createRolloutNodeObject("np-3-node", "v1.27.2"),
+	)
+	managed := func(_ context.Context) (kubernetes.Interface, error) {
+		return nodes, nil
+	}
+
+	v := *testVariables
+	v.KubernetesVersion = "v1.27.2"
+	v.NodePoolRolloutTimeout = "100ms"
+	v.NodePools = []variables.NodePool{
+		{Name: "np-1", Replicas: 1},
+		{Name: "np-2", Replicas: 1},
+		{Name: "np-3", Replicas: 1},
+	}
+	pending, err := pendingNodePools(ctx, ki, di, &v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"np-2", "np-3"}, pending)
+
+	di.ClearActions()
+	assert.NoError(t, c.rolloutNodePools(ctx, ki, di, managed, &v, pending))
+	assertLiveNodePoolVersion(t, di, &v, "np-2", "v1.27.2")
+	assertLiveNodePoolVersion(t, di, &v, "np-3", "v1.27.2")
+
+	// np-1 was already upgraded, and is not applied again
+	for _, action := range di.Actions() {
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			assert.NotEqual(t, "np-1", patch.GetName())
+		}
+	}
+	_, err = ki.CoreV1().ConfigMaps(v.Namespace).Get(ctx, rolloutConfigMapName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+// createRolloutDI creates the live node pools, with running MachinePools that have one node each
+func createRolloutDI(t *testing.T, v variables.Variables) *fake2.FakeDynamicClient {
+	var objects []runtime.Object
+	for _, o := range object.Workers {
+		us, err := object.LoadTextTemplate(o, v)
+		assert.NoError(t, err)
+		for idx := range us {
+			u := &us[idx]
+			if u.GetKind() == "MachinePool" {
+				u.Object["status"] = createRolloutMachinePool(u.GetName(), machinePoolPhaseRunning, u.GetName()+"-node").Object["status"]
+			}
+			objects = append(objects, u)
+		}
+	}
+	return createTestDI(objects...)
+}
+
+func createRolloutMachinePool(name, phase, node string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+			},
+			"status": map[string]interface{}{
+				"phase":         phase,
+				"readyReplicas": int64(1),
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":   readyConditionType,
+						"status": conditionStatusTrue,
+					},
+				},
+				"nodeRefs": []interface{}{
+					map[string]interface{}{
+						"kind": "Node",
+						"name": node,
+					},
+				},
+			},
+		},
+	}
+}
+
+func createRolloutNode(name, kubeletVersion string, ready bool) corev1.Node {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:   corev1.NodeReady,
+					Status: status,
+				},
+			},
+			NodeInfo: corev1.NodeSystemInfo{
+				KubeletVersion: kubeletVersion,
+			},
+		},
+	}
+}
+
+func createRolloutNodeObject(name, kubeletVersion string) *corev1.Node {
+	node := createRolloutNode(name, kubeletVersion, true)
+	return &node
+}
+
+func assertLiveNodePoolVersion(t *testing.T, di dynamic.Interface, v *variables.Variables, name, version string) {
+	mp, err := di.Resource(gvr.MachinePool).Namespace(v.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	liveVersion, _, _ := unstructured.NestedString(mp.Object, "spec", "template", "spec", "version")
+	assert.Equal(t, version, liveVersion)
+}
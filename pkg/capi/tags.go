@@ -0,0 +1,127 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"fmt"
+	"github.com/oracle/oci-go-sdk/v65/containerengine"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// TagNodePools sets the tags of node pools that override the cluster's tags on their OKE node pools, and removes the tags the driver
+// set that a pool no longer has. CAPOCI tags every node pool with the OCIManagedCluster's tags, so pool tags are set through the OCI API
+// once the node pool exists. Other tags CAPOCI and OKE set on the node pool are kept.
+// The tags set on each node pool are recorded in the variables, which must be stored afterwards.
+func TagNodePools(ctx context.Context, di dynamic.Interface, client oci.Client, v *variables.Variables, plog *provisioning.Logger) error {
+	tagged := map[string]variables.Tags{}
+	for _, np := range v.NodePools {
+		previous := v.NodePoolTags[np.Name]
+		if !np.HasTags() && previous.Empty() {
+			continue
+		}
+		ocimp, err := di.Resource(gvr.OCIMachinePools).Namespace(v.Namespace).Get(ctx, np.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		nodePoolId, _, _ := unstructured.NestedString(ocimp.Object, "spec", "id")
+		if nodePoolId == "" {
+			return fmt.Errorf("node pool %s has not been created", np.Name)
+		}
+		nodePool, err := client.GetNodePool(ctx, nodePoolId)
+		if err != nil {
+			return fmt.Errorf("failed to get node pool %s: %v", np.Name, err)
+		}
+		freeformTags, definedTags, changed := mergeNodePoolTags(nodePool, np.Tags(), previous, v.Tags())
+		if changed {
+			if err := client.UpdateNodePoolTags(ctx, nodePoolId, freeformTags, definedTags); err != nil {
+				return fmt.Errorf("failed to tag node pool %s: %v", np.Name, err)
+			}
+			_ = plog.Infof("Tagged node pool %s", np.Name)
+		}
+		if np.HasTags() {
+			tagged[np.Name] = np.Tags()
+		}
+	}
+	v.NodePoolTags = nil
+	if len(tagged) > 0 {
+		v.NodePoolTags = tagged
+	}
+	return nil
+}
+
+// mergeNodePoolTags sets the pool's tags on the node pool's tags. Tags the driver previously set that the pool no longer has are reset
+// to the cluster's tag with the same key, or removed. changed is false if the node pool already has the merged tags.
+func mergeNodePoolTags(nodePool *containerengine.NodePool, desired, previous, cluster variables.Tags) (map[string]string, map[string]map[string]interface{}, bool) {
+	changed := false
+	freeformTags := map[string]string{}
+	for key, value := range nodePool.FreeformTags {
+		freeformTags[key] = value
+	}
+	setFreeformTag := func(key, value string) {
+		if current, ok := freeformTags[key]; !ok || current != value {
+			freeformTags[key] = value
+			changed = true
+		}
+	}
+	for key := range previous.FreeformTags {
+		if _, ok := desired.FreeformTags[key]; ok {
+			continue
+		}
+		if value, ok := cluster.FreeformTags[key]; ok {
+			setFreeformTag(key, value)
+		} else if _, ok := freeformTags[key]; ok {
+			delete(freeformTags, key)
+			changed = true
+		}
+	}
+	for key, value := range desired.FreeformTags {
+		setFreeformTag(key, value)
+	}
+
+	definedTags := map[string]map[string]interface{}{}
+	for namespace, tags := range nodePool.DefinedTags {
+		definedTags[namespace] = map[string]interface{}{}
+		for key, value := range tags {
+			definedTags[namespace][key] = value
+		}
+	}
+	setDefinedTag := func(namespace, key, value string) {
+		if definedTags[namespace] == nil {
+			definedTags[namespace] = map[string]interface{}{}
+		}
+		if current, ok := definedTags[namespace][key]; !ok || current != value {
+			definedTags[namespace][key] = value
+			changed = true
+		}
+	}
+	for namespace, tags := range previous.DefinedTags {
+		for key := range tags {
+			if _, ok := desired.DefinedTags[namespace][key]; ok {
+				continue
+			}
+			if value, ok := cluster.DefinedTags[namespace][key]; ok {
+				setDefinedTag(namespace, key, value)
+			} else if _, ok := definedTags[namespace][key]; ok {
+				delete(definedTags[namespace], key)
+				if len(definedTags[namespace]) == 0 {
+					delete(definedTags, namespace)
+				}
+				changed = true
+			}
+		}
+	}
+	for namespace, tags := range desired.DefinedTags {
+		for key, value := range tags {
+			setDefinedTag(namespace, key, value)
+		}
+	}
+	return freeformTags, definedTags, changed
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/containerengine"
+	"github.com/stretchr/testify/assert"
+	ocifake "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci/fake"
+	fakelogger "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning/fake"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	"testing"
+)
+
+func TestTagNodePools(t *testing.T) {
+	ocimp := func(name, id string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta2",
+			"kind":       "OCIManagedMachinePool",
+			"spec":       map[string]interface{}{},
+		}}
+		u.SetName(name)
+		u.SetNamespace(testVariables.Namespace)
+		if id != "" {
+			_ = unstructured.SetNestedField(u.Object, id, "spec", "id")
+		}
+		return u
+	}
+	capociTags := map[string]string{"CreatedBy": "OCIClusterAPIProvider", "team": "platform"}
+	client := &ocifake.Client{
+		NodePools: map[string]*containerengine.NodePool{
+			"ocid1.nodepool.oc1.phx.gpu": {
+				Id:           common.String("ocid1.nodepool.oc1.phx.gpu"),
+				FreeformTags: capociTags,
+				DefinedTags: map[string]map[string]interface{}{
+					"Oracle-Tags": {"CreatedBy": "rancher"},
+				},
+			},
+			"ocid1.nodepool.oc1.phx.default": {
+				Id:           common.String("ocid1.nodepool.oc1.phx.default"),
+				FreeformTags: capociTags,
+			},
+		},
+	}
+	di := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		ocimp("gpu", "ocid1.nodepool.oc1.phx.gpu"),
+		ocimp("default", "ocid1.nodepool.oc1.phx.default"),
+		ocimp("pending", ""),
+	)
+
+	v := *testVariables
+	v.FreeformTags = map[string]string{"team": "platform"}
+	v.NodePools = []variables.NodePool{
+		{
+			Name:         "gpu",
+			FreeformTags: map[string]string{"team": "ml", "owner": "research"},
+			DefinedTags:  map[string]map[string]string{"Operations": {"CostCenter": "42"}},
+		},
+		{Name: "default"},
+	}
+	gpu := client.NodePools["ocid1.nodepool.oc1.phx.gpu"]
+	assert.NoError(t, TagNodePools(context.TODO(), di, client, &v, fakelogger.NewLogger()))
+	assert.Equal(t, map[string]string{"CreatedBy": "OCIClusterAPIProvider", "team": "ml", "owner": "research"}, gpu.FreeformTags)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"Oracle-Tags": {"CreatedBy": "rancher"},
+		"Operations":  {"CostCenter": "42"},
+	}, gpu.DefinedTags)
+	// pools without tags keep the cluster's tags
+	assert.Equal(t, capociTags, client.NodePools["ocid1.nodepool.oc1.phx.default"].FreeformTags)
+	assert.Equal(t, map[string]variables.Tags{"gpu": v.NodePools[0].Tags()}, v.NodePoolTags)
+
+	// tags removed from a pool are reset to the cluster's tag, or removed
+	v.NodePools[0].FreeformTags = map[string]string{"owner": "research"}
+	v.NodePools[0].DefinedTags = nil
+	assert.NoError(t, TagNodePools(context.TODO(), di, client, &v, fakelogger.NewLogger()))
+	assert.Equal(t, map[string]string{"CreatedBy": "OCIClusterAPIProvider", "team": "platform", "owner": "research"}, gpu.FreeformTags)
+	assert.Equal(t, map[string]map[string]interface{}{"Oracle-Tags": {"CreatedBy": "rancher"}}, gpu.DefinedTags)
+
+	v.NodePools[0].FreeformTags = nil
+	assert.NoError(t, TagNodePools(context.TODO(), di, client, &v, fakelogger.NewLogger()))
+	assert.Equal(t, map[string]string{"CreatedBy": "OCIClusterAPIProvider", "team": "platform"}, gpu.FreeformTags)
+	assert.Nil(t, v.NodePoolTags)
+
+	// node pools are tagged once they are created
+	v.NodePools = append(v.NodePools, variables.NodePool{Name: "pending", FreeformTags: map[string]string{"team": "ml"}})
+	assert.Error(t, TagNodePools(context.TODO(), di, client, &v, fakelogger.NewLogger()))
+}
+
+func TestMergeNodePoolTags(t *testing.T) {
+	nodePool := &containerengine.NodePool{
+		FreeformTags: map[string]string{"team": "ml"},
+		DefinedTags: map[string]map[string]interface{}{
+			"Operations": {"CostCenter": "42"},
+		},
+	}
+	desired := variables.Tags{
+		FreeformTags: map[string]string{"team": "ml"},
+		DefinedTags:  map[string]map[string]string{"Operations": {"CostCenter": "42"}},
+	}
+	_, _, changed := mergeNodePoolTags(nodePool, desired, desired, variables.Tags{})
+	assert.False(t, changed)
+
+	freeformTags, definedTags, changed := mergeNodePoolTags(nodePool, variables.Tags{
+		DefinedTags: map[string]map[string]string{"Operations": {"CostCenter": "43"}},
+	}, variables.Tags{}, variables.Tags{})
+	assert.True(t, changed)
+	assert.Equal(t, map[string]string{"team": "ml"}, freeformTags)
+	assert.Equal(t, map[string]map[string]interface{}{"Operations": {"CostCenter": "43"}}, definedTags)
+	// the live tags are not modified
+	assert.Equal(t, "42", nodePool.DefinedTags["Operations"]["CostCenter"])
+
+	// tags the driver set are reset to the cluster's tags, or removed
+	freeformTags, definedTags, changed = mergeNodePoolTags(nodePool, variables.Tags{}, desired, variables.Tags{
+		DefinedTags: map[string]map[string]string{"Operations": {"CostCenter": "1"}},
+	})
+	assert.True(t, changed)
+	assert.Empty(t, freeformTags)
+	assert.Equal(t, map[string]map[string]interface{}{"Operations": {"CostCenter": "1"}}, definedTags)
+}
@@ -15,28 +15,51 @@ import (
 // UpdateCluster upgrades the CAPI cluster by going through the following stages:
 // 1. update the CAPI credentials using the cloud credential. This keeps the cloud credential up-to-date
 // 2. update the control plane, and then wait for the control plane to be ready
-// 3. update the worker nodes, and then wait for the worker nodes to be ready
-// 4. update the remaining cluster resources, and then wait for the cluster to be ready
-func (c *CAPIClient) UpdateCluster(ctx context.Context, ki kubernetes.Interface, di dynamic.Interface, v *variables.Variables) error {
+// 3. update the worker nodes and the cluster autoscaler, keeping node pools that change version at their current version
+// 4. upgrade the node pools that change version one at a time, unless the rollout is paused, and then wait for the worker nodes to be ready
+// 5. update the remaining cluster resources, and then wait for the cluster to be ready
+// Each wait watches the cluster's objects, and blocks until the cluster is ready or the wait times out.
+func (c *CAPIClient) UpdateCluster(ctx context.Context, ki kubernetes.Interface, di dynamic.Interface, managed ManagedInterface, v *variables.Variables) error {
 	// update the CAPI credentials if necessary
 	if err := createOrUpdateCAPISecret(ctx, v, ki); err != nil {
 		return fmt.Errorf("failed to create CAPI credentials: %v", err)
 	}
 
-	// update the control plane nodes
+	waiter := NewWaiter(di, v, c.plog)
+
+	// update the control plane nodes. Node pools added by the update don't exist until the workers are applied
 	if _, err := createOrUpdateObjects(ctx, di, object.ControlPlane, v); err != nil {
 		return fmt.Errorf("error updating control plane: %v", err)
 	}
-	if err := IsCAPIClusterReady(ctx, di, v, c.plog); err != nil {
+	if err := waiter.Wait(ctx, c.clusterReadyTimeout, ClusterProvisioned); err != nil {
 		return err
 	}
 
-	// update the worker nodes
-	_, err := createOrUpdateObjects(ctx, di, object.Workers, v)
+	// update the worker nodes, leaving version changes to the node pool rollout
+	pending, err := pendingNodePools(ctx, ki, di, v)
 	if err != nil {
+		return err
+	}
+	if err := storeRollout(ctx, ki, v, pending); err != nil {
+		return err
+	}
+	pinned, err := pinNodePoolVersions(ctx, di, v, pending)
+	if err != nil {
+		return err
+	}
+	if err := c.warnPreemptibleNodePools(ctx, di, v); err != nil {
+		return err
+	}
+	if _, err := createOrUpdateObjects(ctx, di, object.Workers, pinned); err != nil {
 		return fmt.Errorf("error updating workers: %v", err)
 	}
-	if err := IsCAPIClusterReady(ctx, di, v, c.plog); err != nil {
+	if err := c.CreateOrDeleteClusterAutoscaler(ctx, di, v); err != nil {
+		return err
+	}
+	if err := c.rolloutNodePools(ctx, ki, di, managed, v, pending); err != nil {
+		return err
+	}
+	if err := waiter.Wait(ctx, c.clusterReadyTimeout, ClusterReady); err != nil {
 		return err
 	}
 
@@ -45,7 +68,7 @@ func (c *CAPIClient) UpdateCluster(ctx context.Context, ki kubernetes.Interface,
 		return fmt.Errorf("error updating cluster resources: %v", err)
 	}
 
-	if err := IsCAPIClusterReady(ctx, di, v, c.plog); err != nil {
+	if err := waiter.Wait(ctx, c.clusterReadyTimeout, ClusterReady); err != nil {
 		return err
 	}
 	return c.DeleteHangingResources(ctx, di, v)
@@ -6,13 +6,118 @@ package capi
 import (
 	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/capi/object"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning"
+	fakelogger "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning/fake"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"testing"
+	"time"
 )
 
 func TestUpdateCluster(t *testing.T) {
 	di := createTestDIWithClusterAndMachine()
 	ki := fake.NewSimpleClientset()
-	err := testCAPIClient.UpdateCluster(context.TODO(), ki, di, testVariables)
+	managed := func(_ context.Context) (kubernetes.Interface, error) {
+		return fake.NewSimpleClientset(), nil
+	}
+	err := testCAPIClient.UpdateCluster(context.TODO(), ki, di, managed, testVariables)
 	assert.NoError(t, err)
 }
+
+func TestUpdateClusterAddsNodePool(t *testing.T) {
+	ctx := context.TODO()
+	c := NewCAPIClient(fakelogger.NewLogger())
+	c.clusterReadyTimeout = 5 * time.Second
+
+	live := *testVariables
+	live.NodePools = []variables.NodePool{{Name: "np-1", Replicas: 1}}
+	objects := []runtime.Object{
+		createTestCluster(&live, true, true, clusterPhaseProvisioned),
+		createTestControlPlane(&live, true),
+	}
+	for _, o := range object.Workers {
+		us, err := object.LoadTextTemplate(o, live)
+		assert.NoError(t, err)
+		for idx := range us {
+			objects = append(objects, readyNodePoolObject(&us[idx]))
+		}
+	}
+	di := createTestDI(objects...)
+	// np-2's objects are created by the update, and become ready once they are applied
+	di.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch, ok := action.(k8stesting.PatchAction)
+		if !ok || patch.GetName() != "np-2" {
+			return false, nil, nil
+		}
+		if _, err := di.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName()); err == nil {
+			return false, nil, nil
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		u = readyNodePoolObject(u)
+		return true, u, di.Tracker().Create(patch.GetResource(), u, patch.GetNamespace())
+	})
+	managed := func(_ context.Context) (kubernetes.Interface, error) {
+		return fake.NewSimpleClientset(), nil
+	}
+
+	v := live
+	v.NodePools = []variables.NodePool{{Name: "np-1", Replicas: 1}, {Name: "np-2", Replicas: 1}}
+	assert.NoError(t, c.UpdateCluster(ctx, fake.NewSimpleClientset(), di, managed, &v))
+	for _, resource := range []schema.GroupVersionResource{gvr.MachinePool, gvr.OCIMachinePools} {
+		_, err := di.Resource(resource).Namespace(v.Namespace).Get(ctx, "np-2", metav1.GetOptions{})
+		assert.NoError(t, err)
+	}
+}
+
+func TestUpdateClusterWarnsPreemptibleNodePools(t *testing.T) {
+	ctx := context.TODO()
+	ki := fake.NewSimpleClientset()
+	c := NewCAPIClient(provisioning.NewLogger(ctx, ki, testName))
+	c.clusterReadyTimeout = 5 * time.Second
+	di := createTestDI(createTestCluster(testVariables, true, true, clusterPhaseProvisioned), createTestControlPlane(testVariables, true))
+	// the preemptible pool is created by the update, and becomes ready once it is applied
+	di.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch, ok := action.(k8stesting.PatchAction)
+		if !ok || patch.GetName() != "batch" {
+			return false, nil, nil
+		}
+		if _, err := di.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName()); err == nil {
+			return false, nil, nil
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		u = readyNodePoolObject(u)
+		return true, u, di.Tracker().Create(patch.GetResource(), u, patch.GetNamespace())
+	})
+	managed := func(_ context.Context) (kubernetes.Interface, error) {
+		return fake.NewSimpleClientset(), nil
+	}
+
+	v := *testVariables
+	v.NodePools = []variables.NodePool{{Name: "batch", Replicas: 1, CapacityType: variables.CapacityTypePreemptible}}
+	assert.NoError(t, c.UpdateCluster(ctx, ki, di, managed, &v))
+	cm, err := ki.CoreV1().ConfigMaps(testName).Get(ctx, "provisioning-log", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, cm.Data["log"], "[WARN] Creating preemptible node pool batch")
+}
+
+// readyNodePoolObject marks a node pool's MachinePool or OCIManagedMachinePool as ready
+func readyNodePoolObject(u *unstructured.Unstructured) *unstructured.Unstructured {
+	if u.GetKind() == "MachinePool" || u.GetKind() == "OCIManagedMachinePool" {
+		u.Object["status"] = createRolloutMachinePool(u.GetName(), machinePoolPhaseRunning, u.GetName()+"-node").Object["status"]
+	}
+	return u
+}
@@ -116,13 +116,12 @@ func getVerrazzanoFleet(v *variables.Variables) (*unstructured.Unstructured, err
 }
 
 func createOrUpdateVerrazzano(ctx context.Context, di dynamic.Interface, v *variables.Variables) error {
-	if _, err := cruObject(ctx, di, object.Object{
-		Text: templates.VerrazzanoFleet,
-	}, v, func(u *unstructured.Unstructured) error {
-		return unstructured.SetNestedField(u.Object, v.VerrazzanoVersion, "spec", "verrazzano", "spec", "version")
-	}); err != nil {
+	vzFleet, err := getVerrazzanoFleet(v)
+	if err != nil {
 		return err
 	}
-
-	return nil
+	if err := unstructured.SetNestedField(vzFleet.Object, v.VerrazzanoVersion, "spec", "verrazzano", "spec", "version"); err != nil {
+		return fmt.Errorf("failed to set Verrazzano version: %v", err)
+	}
+	return createOrUpdateUnstructured(ctx, di, vzFleet, nil)
 }
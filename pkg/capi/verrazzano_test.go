@@ -23,7 +23,7 @@ func TestUpdateVerrazzano(t *testing.T) {
 
 	c := NewCAPIClient(fakelogger.NewLogger())
 	scheme := runtime.NewScheme()
-	adminDi := fake2.NewSimpleDynamicClient(scheme)
+	adminDi := withApplyReactor(fake2.NewSimpleDynamicClient(scheme))
 	err := c.UpdateVerrazzano(context.TODO(), adminDi, v)
 	assert.NoError(t, err)
 }
\ No newline at end of file
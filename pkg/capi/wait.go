@@ -0,0 +1,203 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"fmt"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClusterObjects is a point in time view of the CAPI objects that make up a cluster
+type ClusterObjects struct {
+	Cluster         *unstructured.Unstructured
+	ControlPlane    *unstructured.Unstructured
+	MachinePools    []*unstructured.Unstructured
+	OCIMachinePools []*unstructured.Unstructured
+}
+
+// ReadyCondition is true when the cluster's objects have reached a desired state
+type ReadyCondition func(state *variables.Variables, objects *ClusterObjects) bool
+
+// Waiter watches a cluster's CAPI objects until a ReadyCondition holds
+type Waiter struct {
+	di    dynamic.Interface
+	state *variables.Variables
+	plog  *provisioning.Logger
+}
+
+// watchedResources are the CAPI resources a cluster's readiness depends on
+var watchedResources = []schema.GroupVersionResource{
+	gvr.Cluster,
+	gvr.OCIControlPlane,
+	gvr.MachinePool,
+	gvr.OCIMachinePools,
+}
+
+func NewWaiter(di dynamic.Interface, state *variables.Variables, plog *provisioning.Logger) *Waiter {
+	return &Waiter{
+		di:    di,
+		state: state,
+		plog:  plog,
+	}
+}
+
+// Wait blocks until the condition holds for the cluster's objects, or the timeout is reached.
+// The condition is evaluated each time a watched object changes, and every change in the cluster's state is logged.
+func (w *Waiter) Wait(ctx context.Context, timeout time.Duration, condition ReadyCondition) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// the cluster's objects all live in the cluster namespace
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(w.di, 0, w.state.Namespace, nil)
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+	listers := map[schema.GroupVersionResource]cache.GenericLister{}
+	for _, resource := range watchedResources {
+		informer := factory.ForResource(resource)
+		informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(_ interface{}) { notify() },
+			UpdateFunc: func(_, _ interface{}) { notify() },
+			DeleteFunc: func(_ interface{}) { notify() },
+		})
+		listers[resource] = informer.Lister()
+	}
+	factory.Start(ctx.Done())
+	for resource, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("timed out watching %s for cluster %s", resource.Resource, w.state.Name)
+		}
+	}
+
+	var lastState string
+	for {
+		objects := cachedClusterObjects(listers, w.state)
+		if clusterState := objects.Describe(); clusterState != lastState {
+			_ = w.plog.Infof("%s", clusterState)
+			lastState = clusterState
+		}
+		if condition(w.state, objects) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for cluster %s to be ready: %s", w.state.Name, lastState)
+		case <-changes:
+		}
+	}
+}
+
+func cachedClusterObjects(listers map[schema.GroupVersionResource]cache.GenericLister, state *variables.Variables) *ClusterObjects {
+	objects := &ClusterObjects{
+		Cluster:         cachedObject(listers[gvr.Cluster], state.Namespace, state.Name),
+		MachinePools:    cachedObjects(listers[gvr.MachinePool], state.Namespace),
+		OCIMachinePools: cachedObjects(listers[gvr.OCIMachinePools], state.Namespace),
+	}
+	objects.ControlPlane = cachedObject(listers[gvr.OCIControlPlane], state.Namespace, objects.controlPlaneName(state))
+	return objects
+}
+
+func cachedObject(lister cache.GenericLister, namespace, name string) *unstructured.Unstructured {
+	o, err := lister.ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil
+	}
+	u, ok := o.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	return u
+}
+
+func cachedObjects(lister cache.GenericLister, namespace string) []*unstructured.Unstructured {
+	os, err := lister.ByNamespace(namespace).List(labels.Everything())
+	if err != nil {
+		return nil
+	}
+	var us []*unstructured.Unstructured
+	for _, o := range os {
+		if u, ok := o.(*unstructured.Unstructured); ok {
+			us = append(us, u)
+		}
+	}
+	// listers are unordered, sort so the cluster's state is described consistently
+	sort.Slice(us, func(i, j int) bool {
+		return us[i].GetName() < us[j].GetName()
+	})
+	return us
+}
+
+// controlPlaneName is the control plane referenced by the cluster, or the templated name if the cluster is not found
+func (o *ClusterObjects) controlPlaneName(state *variables.Variables) string {
+	if o.Cluster != nil {
+		if name, ok, _ := unstructured.NestedString(o.Cluster.Object, "spec", "controlPlaneRef", "name"); ok {
+			return name
+		}
+	}
+	return state.DisplayName
+}
+
+// Describe aggregates the Ready conditions of the cluster's objects, and the state of the cluster's add-ons, into a single message
+func (o *ClusterObjects) Describe() string {
+	var states []string
+	states = append(states, describeObject("Cluster", o.Cluster))
+	states = append(states, describeObject("control plane", o.ControlPlane))
+	states = append(states, describeAddons(o.ControlPlane)...)
+	for _, mp := range o.MachinePools {
+		states = append(states, describeObject("node pool "+mp.GetName(), mp))
+	}
+	return strings.Join(states, ", ")
+}
+
+func describeObject(component string, u *unstructured.Unstructured) string {
+	if u == nil {
+		return component + " not found"
+	}
+	phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+	ready := readyCondition(u)
+	switch {
+	case ready == nil && phase == "":
+		return component + " initializing"
+	case ready == nil:
+		return fmt.Sprintf("%s %s", component, phase)
+	case ready["status"] == conditionStatusTrue:
+		return component + " ready"
+	}
+	msg := fmt.Sprintf("%s not ready", component)
+	if reason, ok := ready["reason"].(string); ok && reason != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, reason)
+	}
+	if message, ok := ready["message"].(string); ok && message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, message)
+	}
+	return msg
+}
+
+// readyCondition finds the object's Ready condition, if present
+func readyCondition(u *unstructured.Unstructured) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, condition := range conditions {
+		c, ok := condition.(map[string]interface{})
+		if ok && c["type"] == readyConditionType {
+			return c
+		}
+	}
+	return nil
+}
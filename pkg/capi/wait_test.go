@@ -0,0 +1,69 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package capi
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
+	fakelogger "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+	"time"
+)
+
+func TestWaitForClusterReady(t *testing.T) {
+	ctx := context.TODO()
+	di := createTestDI(createTestCluster(testVariables, false, false, "Provisioning"), createTestControlPlane(testVariables, true))
+	waiter := NewWaiter(di, testVariables, fakelogger.NewLogger())
+
+	done := make(chan error)
+	go func() {
+		done <- waiter.Wait(ctx, time.Minute, ClusterReady)
+	}()
+
+	// the cluster becomes ready while waiting
+	cluster := createTestCluster(testVariables, true, true, clusterPhaseProvisioned)
+	cluster.SetResourceVersion("2")
+	_, err := di.Resource(gvr.Cluster).Namespace(testName).Update(ctx, cluster, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, <-done)
+}
+
+func TestWaitTimeout(t *testing.T) {
+	di := createTestDI(createTestCluster(testVariables, false, false, "Provisioning"))
+	err := NewWaiter(di, testVariables, fakelogger.NewLogger()).Wait(context.TODO(), time.Second, ClusterReady)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestDescribeClusterObjects(t *testing.T) {
+	cluster := createTestCluster(testVariables, true, true, clusterPhaseProvisioned)
+	cluster.Object["status"].(map[string]interface{})["conditions"] = []interface{}{
+		map[string]interface{}{
+			"type":   "Ready",
+			"status": "True",
+		},
+	}
+	pool := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":    "Ready",
+					"status":  "False",
+					"reason":  "WaitingForReplicasReady",
+					"message": "1 of 2 replicas ready",
+				},
+			},
+		},
+	}}
+	pool.SetName("np-1")
+
+	objects := &ClusterObjects{
+		Cluster:      cluster,
+		MachinePools: []*unstructured.Unstructured{pool},
+	}
+	assert.Equal(t, "Cluster ready, control plane not found, node pool np-1 not ready (WaitingForReplicasReady): 1 of 2 replicas ready", objects.Describe())
+}
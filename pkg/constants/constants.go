@@ -11,6 +11,7 @@ const (
 
 	CompartmentID      = "compartment-id"
 	QuickCreateVCN     = "quick-create-vcn"
+	VCNCIDR            = "vcn-cidr"
 	CNIType            = "cni-type"
 	VcnID              = "vcn-id"
 	WorkerNodeSubnet   = "worker-node-subnet"
@@ -19,14 +20,35 @@ const (
 	PodSubnet          = "pod-subnet"
 	PodCIDR            = "pod-cidr"
 	ClusterCIDR        = "cluster-cidr"
+	PrivateEndpoint    = "private-endpoint"
+	APIServerEndpoint  = "api-server-endpoint"
+	APIServerProxy     = "api-server-proxy"
 	ImageDisplayName   = "image-display-name"
 	ImageId            = "image-id"
 
-	RawNodePools = "node-pools"
-	ApplyYAMLs   = "apply-yamls"
+	KMSKeyID                = "kms-key-id"
+	NodePVTransitEncryption = "node-pv-transit-encryption"
+
+	RawFreeformTags = "freeform-tags"
+	RawDefinedTags  = "defined-tags"
+
+	ImageVerification          = "image-verification"
+	ImageVerificationKMSKeyIDs = "image-verification-kms-key-ids"
+
+	RawNodePools           = "node-pools"
+	NodePoolDistribution   = "node-pool-distribution"
+	ClusterAutoscalerImage = "cluster-autoscaler-image"
+	RawAddons              = "addons"
+	ApplyYAMLs             = "apply-yamls"
+
+	NodeCyclingMaxSurge       = "node-cycling-max-surge"
+	NodeCyclingMaxUnavailable = "node-cycling-max-unavailable"
+	NodeEvictionGraceDuration = "node-eviction-grace-duration"
+	NodeEvictionForceDelete   = "node-eviction-force-delete"
 
 	CloudCredentialId = "cloud-credential-id"
 	Region            = "region"
+	AuthType          = "auth-type"
 
 	InstallVerrazzano  = "install-verrazzano"
 	VerrazzanoResource = "verrazzano-resource"
@@ -39,4 +61,14 @@ const (
 	ImagePullSecretUsername = "image-pull-secret-username"
 	ImagePullSecretPassword = "image-pull-secret-password"
 	ImagePullSecretEmail    = "image-pull-secret-email"
+
+	SnapshotBackend   = "snapshot-backend"
+	SnapshotBucket    = "snapshot-bucket"
+	SnapshotNamespace = "snapshot-namespace"
+	SnapshotDirectory = "snapshot-directory"
+	SnapshotSecrets   = "snapshot-secrets"
+
+	PlanUpdate             = "plan-update"
+	PauseNodePoolRollout   = "pause-node-pool-rollout"
+	NodePoolRolloutTimeout = "node-pool-rollout-timeout"
 )
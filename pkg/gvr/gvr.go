@@ -26,6 +26,12 @@ var OCICluster = schema.GroupVersionResource{
 	Resource: "ocimanagedclusters",
 }
 
+var OCIControlPlane = schema.GroupVersionResource{
+	Group:    InfrastructureXK8sIO,
+	Version:  V1Beta2Version,
+	Resource: "ocimanagedcontrolplanes",
+}
+
 var ClusterIdentity = schema.GroupVersionResource{
 	Group:    InfrastructureXK8sIO,
 	Version:  V1Beta1Version,
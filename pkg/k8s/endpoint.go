@@ -0,0 +1,98 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package k8s
+
+import (
+	"fmt"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"net/url"
+	"strings"
+)
+
+var proxySchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"socks5": true,
+}
+
+// SetAPIServer overrides the API server address and proxy of each cluster in a kubeconfig, so a private API endpoint can be reached
+// through a tunnel or proxy. The original server's host is kept as the TLS server name, so the serving certificate still verifies.
+func SetAPIServer(kubeconfig []byte, server, proxyURL string) ([]byte, error) {
+	if server == "" && proxyURL == "" {
+		return kubeconfig, nil
+	}
+	if server != "" && !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	if server != "" {
+		u, err := url.Parse(server)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid API server endpoint %s", server)
+		}
+	}
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Host == "" || !proxySchemes[u.Scheme] {
+			return nil, fmt.Errorf("invalid API server proxy %s, must be an http, https or socks5 URL", proxyURL)
+		}
+	}
+
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, cluster := range config.Clusters {
+		if server != "" && server != cluster.Server {
+			if cluster.TLSServerName == "" {
+				original, err := url.Parse(cluster.Server)
+				if err != nil {
+					return nil, err
+				}
+				cluster.TLSServerName = original.Hostname()
+			}
+			cluster.Server = server
+		}
+		if proxyURL != "" {
+			cluster.ProxyURL = proxyURL
+		}
+	}
+	return clientcmd.Write(*config)
+}
+
+// APIServer returns the cluster of a kubeconfig's current context, such as a kubeconfig with the overrides of SetAPIServer
+func APIServer(kubeconfig []byte) (*clientcmdapi.Cluster, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if context, ok := config.Contexts[config.CurrentContext]; ok {
+		if cluster, ok := config.Clusters[context.Cluster]; ok {
+			return cluster, nil
+		}
+	}
+	if len(config.Clusters) == 1 {
+		for _, cluster := range config.Clusters {
+			return cluster, nil
+		}
+	}
+	return nil, fmt.Errorf("kubeconfig has no cluster for context %s", config.CurrentContext)
+}
+
+// RancherAPIServer is the API server reported to Rancher for a kubeconfig, and the same kubeconfig with the overrides of SetAPIServer.
+// Rancher only receives the endpoint and CA, so it can't verify an overridden endpoint with the original TLS server name.
+// The original endpoint is reported instead, with the overridden proxy.
+func RancherAPIServer(original, overridden []byte) (*clientcmdapi.Cluster, error) {
+	cluster, err := APIServer(original)
+	if err != nil {
+		return nil, err
+	}
+	apiServer, err := APIServer(overridden)
+	if err != nil {
+		return nil, err
+	}
+	reported := cluster.DeepCopy()
+	reported.ProxyURL = apiServer.ProxyURL
+	return reported, nil
+}
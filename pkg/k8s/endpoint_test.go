@@ -0,0 +1,132 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package k8s
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	"testing"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://10.0.0.10:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abc
+`
+
+func TestSetAPIServer(t *testing.T) {
+	var tests = []struct {
+		name          string
+		server        string
+		proxyURL      string
+		host          string
+		tlsServerName string
+		proxy         string
+		hasError      bool
+	}{
+		{
+			"no overrides",
+			"",
+			"",
+			"https://10.0.0.10:6443",
+			"",
+			"",
+			false,
+		},
+		{
+			"tunnel address",
+			"127.0.0.1:6443",
+			"",
+			"https://127.0.0.1:6443",
+			"10.0.0.10",
+			"",
+			false,
+		},
+		{
+			"proxy",
+			"",
+			"socks5://bastion:1080",
+			"https://10.0.0.10:6443",
+			"",
+			"socks5://bastion:1080",
+			false,
+		},
+		{
+			"unsupported proxy",
+			"",
+			"ftp://bastion:21",
+			"",
+			"",
+			"",
+			true,
+		},
+		{
+			"invalid server",
+			"https://",
+			"",
+			"",
+			"",
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeconfig, err := SetAPIServer([]byte(testKubeConfig), tt.server, tt.proxyURL)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.host, config.Host)
+			assert.Equal(t, tt.tlsServerName, config.TLSClientConfig.ServerName)
+			assert.Equal(t, "abc", config.BearerToken)
+			if tt.proxy == "" {
+				assert.Nil(t, config.Proxy)
+				return
+			}
+			proxy, err := config.Proxy(nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.proxy, proxy.String())
+		})
+	}
+}
+
+func TestAPIServer(t *testing.T) {
+	kubeconfig, err := SetAPIServer([]byte(testKubeConfig), "127.0.0.1:6443", "socks5://bastion:1080")
+	assert.NoError(t, err)
+	cluster, err := APIServer(kubeconfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", cluster.Server)
+	assert.Equal(t, "socks5://bastion:1080", cluster.ProxyURL)
+
+	_, err = APIServer([]byte("apiVersion: v1\nkind: Config\n"))
+	assert.Error(t, err)
+}
+
+func TestRancherAPIServer(t *testing.T) {
+	kubeconfig, err := SetAPIServer([]byte(testKubeConfig), "tunnel.example.com:6443", "socks5://bastion:1080")
+	assert.NoError(t, err)
+	// the tunnel is only verified with the original TLS server name, so Rancher is given the original endpoint
+	cluster, err := RancherAPIServer([]byte(testKubeConfig), kubeconfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.10:6443", cluster.Server)
+	assert.Empty(t, cluster.TLSServerName)
+	assert.Equal(t, "socks5://bastion:1080", cluster.ProxyURL)
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package fake
+
+import (
+	"context"
+	"fmt"
+)
+
+type ObjectStorage struct {
+	Namespace string
+	Objects   map[string][]byte
+}
+
+// GetNamespace retrieves the Object Storage namespace of the tenancy
+func (o *ObjectStorage) GetNamespace(_ context.Context) (string, error) {
+	return o.Namespace, nil
+}
+
+// PutObject uploads data to the named object in a bucket
+func (o *ObjectStorage) PutObject(_ context.Context, namespace, bucket, name string, data []byte) error {
+	if o.Objects == nil {
+		o.Objects = map[string][]byte{}
+	}
+	o.Objects[objectKey(namespace, bucket, name)] = data
+	return nil
+}
+
+// GetObject downloads the named object from a bucket
+func (o *ObjectStorage) GetObject(_ context.Context, namespace, bucket, name string) ([]byte, error) {
+	data, ok := o.Objects[objectKey(namespace, bucket, name)]
+	if !ok {
+		return nil, fmt.Errorf("no object found for %s/%s/%s", namespace, bucket, name)
+	}
+	return data, nil
+}
+
+// DeleteObject deletes the named object from a bucket, if it exists
+func (o *ObjectStorage) DeleteObject(_ context.Context, namespace, bucket, name string) error {
+	delete(o.Objects, objectKey(namespace, bucket, name))
+	return nil
+}
+
+func objectKey(namespace, bucket, name string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, bucket, name)
+}
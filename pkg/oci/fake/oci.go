@@ -6,12 +6,22 @@ package fake
 import (
 	"context"
 	"fmt"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/containerengine"
 	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	"github.com/oracle/oci-go-sdk/v65/keymanagement"
 )
 
 type Client struct {
-	Images  map[string]string
-	Subnets map[string]*core.Subnet
+	Images              map[string]string
+	Subnets             map[string]*core.Subnet
+	KubernetesVersions  []string
+	AvailabilityDomains []string
+	Regions             []identity.Region
+	Shapes              []core.Shape
+	KMSKeys             []keymanagement.KeySummary
+	NodePools           map[string]*containerengine.NodePool
 }
 
 // GetImageIdByName retrieves an image OCID given an image name and a compartment id, if that image exists.
@@ -23,6 +33,19 @@ func (c *Client) GetImageIdByName(ctx context.Context, displayName, compartmentI
 	return imageId, nil
 }
 
+// GetImageById retrieves an image given that image's Id.
+func (c *Client) GetImageById(ctx context.Context, imageId string) (*core.Image, error) {
+	for displayName, id := range c.Images {
+		if id == imageId {
+			return &core.Image{
+				Id:          common.String(id),
+				DisplayName: common.String(displayName),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("no image found for %s", imageId)
+}
+
 // GetSubnetById retrieves a subnet given that subnet's Id.
 func (c *Client) GetSubnetById(ctx context.Context, subnetId string) (*core.Subnet, error) {
 	subnet, ok := c.Subnets[subnetId]
@@ -31,3 +54,48 @@ func (c *Client) GetSubnetById(ctx context.Context, subnetId string) (*core.Subn
 	}
 	return subnet, nil
 }
+
+// GetKubernetesVersions retrieves the Kubernetes versions supported by OKE
+func (c *Client) GetKubernetesVersions(ctx context.Context, compartmentId string) ([]string, error) {
+	return c.KubernetesVersions, nil
+}
+
+// GetAvailabilityDomains retrieves the names of the availability domains in the region
+func (c *Client) GetAvailabilityDomains(ctx context.Context, compartmentId string) ([]string, error) {
+	return c.AvailabilityDomains, nil
+}
+
+// GetRegions retrieves the regions of every realm
+func (c *Client) GetRegions(ctx context.Context) ([]identity.Region, error) {
+	return c.Regions, nil
+}
+
+// GetShapes retrieves the compute shapes available in a compartment, with their OCPU and memory limits
+func (c *Client) GetShapes(ctx context.Context, compartmentId string) ([]core.Shape, error) {
+	return c.Shapes, nil
+}
+
+// GetKMSKeys retrieves the keys of the active vaults in a compartment
+func (c *Client) GetKMSKeys(ctx context.Context, compartmentId string) ([]keymanagement.KeySummary, error) {
+	return c.KMSKeys, nil
+}
+
+// GetNodePool retrieves an OKE node pool given that node pool's Id.
+func (c *Client) GetNodePool(ctx context.Context, nodePoolId string) (*containerengine.NodePool, error) {
+	nodePool, ok := c.NodePools[nodePoolId]
+	if !ok {
+		return nil, fmt.Errorf("no node pool found for %s", nodePoolId)
+	}
+	return nodePool, nil
+}
+
+// UpdateNodePoolTags replaces the tags of an OKE node pool, and of the nodes it launches
+func (c *Client) UpdateNodePoolTags(ctx context.Context, nodePoolId string, freeformTags map[string]string, definedTags map[string]map[string]interface{}) error {
+	nodePool, ok := c.NodePools[nodePoolId]
+	if !ok {
+		return fmt.Errorf("no node pool found for %s", nodePoolId)
+	}
+	nodePool.FreeformTags = freeformTags
+	nodePool.DefinedTags = definedTags
+	return nil
+}
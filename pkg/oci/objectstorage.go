@@ -0,0 +1,85 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+	"io"
+	"net/http"
+)
+
+// ObjectStorage interface for OCI Object Storage clients
+type ObjectStorage interface {
+	GetNamespace(ctx context.Context) (string, error)
+	PutObject(ctx context.Context, namespace, bucket, name string, data []byte) error
+	GetObject(ctx context.Context, namespace, bucket, name string) ([]byte, error)
+	DeleteObject(ctx context.Context, namespace, bucket, name string) error
+}
+
+// ObjectStorageImpl OCI Object Storage client implementation
+type ObjectStorageImpl struct {
+	client objectstorage.ObjectStorageClient
+}
+
+// NewObjectStorage creates a new OCI Object Storage client
+func NewObjectStorage(provider common.ConfigurationProvider) (ObjectStorage, error) {
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectStorageImpl{
+		client: client,
+	}, nil
+}
+
+// GetNamespace retrieves the Object Storage namespace of the tenancy
+func (c *ObjectStorageImpl) GetNamespace(ctx context.Context) (string, error) {
+	response, err := c.client.GetNamespace(ctx, objectstorage.GetNamespaceRequest{})
+	if err != nil {
+		return "", err
+	}
+	return *response.Value, nil
+}
+
+// PutObject uploads data to the named object in a bucket
+func (c *ObjectStorageImpl) PutObject(ctx context.Context, namespace, bucket, name string, data []byte) error {
+	_, err := c.client.PutObject(ctx, objectstorage.PutObjectRequest{
+		NamespaceName: &namespace,
+		BucketName:    &bucket,
+		ObjectName:    &name,
+		ContentLength: common.Int64(int64(len(data))),
+		PutObjectBody: io.NopCloser(bytes.NewReader(data)),
+	})
+	return err
+}
+
+// GetObject downloads the named object from a bucket
+func (c *ObjectStorageImpl) GetObject(ctx context.Context, namespace, bucket, name string) ([]byte, error) {
+	response, err := c.client.GetObject(ctx, objectstorage.GetObjectRequest{
+		NamespaceName: &namespace,
+		BucketName:    &bucket,
+		ObjectName:    &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer response.Content.Close()
+	return io.ReadAll(response.Content)
+}
+
+// DeleteObject deletes the named object from a bucket, if it exists
+func (c *ObjectStorageImpl) DeleteObject(ctx context.Context, namespace, bucket, name string) error {
+	_, err := c.client.DeleteObject(ctx, objectstorage.DeleteObjectRequest{
+		NamespaceName: &namespace,
+		BucketName:    &bucket,
+		ObjectName:    &name,
+	})
+	if serviceErr, ok := common.IsServiceError(err); ok && serviceErr.GetHTTPStatusCode() == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/oracle/oci-go-sdk/v65/common"
 	"github.com/oracle/oci-go-sdk/v65/containerengine"
 	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	"github.com/oracle/oci-go-sdk/v65/keymanagement"
 )
 
 const (
@@ -20,12 +22,25 @@ const (
 type Client interface {
 	GetSubnetById(context.Context, string) (*core.Subnet, error)
 	GetImageIdByName(ctx context.Context, displayName, compartmentId string) (string, error)
+	GetImageById(ctx context.Context, imageId string) (*core.Image, error)
+	GetKubernetesVersions(ctx context.Context, compartmentId string) ([]string, error)
+	GetAvailabilityDomains(ctx context.Context, compartmentId string) ([]string, error)
+	GetRegions(ctx context.Context) ([]identity.Region, error)
+	GetShapes(ctx context.Context, compartmentId string) ([]core.Shape, error)
+	GetKMSKeys(ctx context.Context, compartmentId string) ([]keymanagement.KeySummary, error)
+	GetNodePool(ctx context.Context, nodePoolId string) (*containerengine.NodePool, error)
+	UpdateNodePoolTags(ctx context.Context, nodePoolId string, freeformTags map[string]string, definedTags map[string]map[string]interface{}) error
 }
 
 // ClientImpl OCI Client implementation
 type ClientImpl struct {
 	vnClient              core.VirtualNetworkClient
+	computeClient         core.ComputeClient
 	containerEngineClient containerengine.ContainerEngineClient
+	identityClient        identity.IdentityClient
+	kmsVaultClient        keymanagement.KmsVaultClient
+	// provider creates KMS management clients, which are specific to each vault
+	provider common.ConfigurationProvider
 }
 
 // NewClient creates a new OCI Client
@@ -35,14 +50,33 @@ func NewClient(provider common.ConfigurationProvider) (Client, error) {
 		return nil, err
 	}
 
+	computeClient, err := core.NewComputeClientWithConfigurationProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+
 	containerEngineClient, err := containerengine.NewContainerEngineClientWithConfigurationProvider(provider)
 	if err != nil {
 		return nil, err
 	}
 
+	identityClient, err := identity.NewIdentityClientWithConfigurationProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	kmsVaultClient, err := keymanagement.NewKmsVaultClientWithConfigurationProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ClientImpl{
 		vnClient:              net,
+		computeClient:         computeClient,
 		containerEngineClient: containerEngineClient,
+		identityClient:        identityClient,
+		kmsVaultClient:        kmsVaultClient,
+		provider:              provider,
 	}, nil
 }
 
@@ -64,6 +98,158 @@ func (c *ClientImpl) GetImageIdByName(ctx context.Context, displayName, compartm
 	return "", fmt.Errorf("no images found for %s/%s", compartmentId, displayName)
 }
 
+// GetImageById retrieves an image given that image's Id.
+func (c *ClientImpl) GetImageById(ctx context.Context, imageId string) (*core.Image, error) {
+	response, err := c.computeClient.GetImage(ctx, core.GetImageRequest{
+		ImageId: &imageId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response.Image, nil
+}
+
+// GetKubernetesVersions retrieves the Kubernetes versions supported by OKE
+func (c *ClientImpl) GetKubernetesVersions(ctx context.Context, compartmentId string) ([]string, error) {
+	options, err := c.containerEngineClient.GetClusterOptions(ctx, containerengine.GetClusterOptionsRequest{
+		ClusterOptionId: common.String("all"),
+		CompartmentId:   &compartmentId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return options.KubernetesVersions, nil
+}
+
+// GetAvailabilityDomains retrieves the names of the availability domains in the region
+func (c *ClientImpl) GetAvailabilityDomains(ctx context.Context, compartmentId string) ([]string, error) {
+	response, err := c.identityClient.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{
+		CompartmentId: &compartmentId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var availabilityDomains []string
+	for _, ad := range response.Items {
+		if ad.Name != nil {
+			availabilityDomains = append(availabilityDomains, *ad.Name)
+		}
+	}
+	return availabilityDomains, nil
+}
+
+// GetRegions retrieves the regions of every realm
+func (c *ClientImpl) GetRegions(ctx context.Context) ([]identity.Region, error) {
+	response, err := c.identityClient.ListRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+// GetShapes retrieves the compute shapes available in a compartment, with their OCPU and memory limits
+func (c *ClientImpl) GetShapes(ctx context.Context, compartmentId string) ([]core.Shape, error) {
+	var shapes []core.Shape
+	seen := map[string]bool{}
+	request := core.ListShapesRequest{
+		CompartmentId: &compartmentId,
+	}
+	for {
+		response, err := c.computeClient.ListShapes(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		// shapes are listed once per availability domain
+		for _, shape := range response.Items {
+			if shape.Shape != nil && !seen[*shape.Shape] {
+				seen[*shape.Shape] = true
+				shapes = append(shapes, shape)
+			}
+		}
+		if response.OpcNextPage == nil {
+			return shapes, nil
+		}
+		request.Page = response.OpcNextPage
+	}
+}
+
+// GetKMSKeys retrieves the keys of the active vaults in a compartment
+func (c *ClientImpl) GetKMSKeys(ctx context.Context, compartmentId string) ([]keymanagement.KeySummary, error) {
+	var keys []keymanagement.KeySummary
+	vaultsRequest := keymanagement.ListVaultsRequest{
+		CompartmentId: &compartmentId,
+	}
+	for {
+		vaults, err := c.kmsVaultClient.ListVaults(ctx, vaultsRequest)
+		if err != nil {
+			return nil, err
+		}
+		for _, vault := range vaults.Items {
+			if vault.LifecycleState != keymanagement.VaultSummaryLifecycleStateActive || vault.ManagementEndpoint == nil {
+				continue
+			}
+			vaultKeys, err := c.getVaultKeys(ctx, compartmentId, *vault.ManagementEndpoint)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, vaultKeys...)
+		}
+		if vaults.OpcNextPage == nil {
+			return keys, nil
+		}
+		vaultsRequest.Page = vaults.OpcNextPage
+	}
+}
+
+func (c *ClientImpl) getVaultKeys(ctx context.Context, compartmentId, managementEndpoint string) ([]keymanagement.KeySummary, error) {
+	kmsClient, err := keymanagement.NewKmsManagementClientWithConfigurationProvider(c.provider, managementEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	var keys []keymanagement.KeySummary
+	request := keymanagement.ListKeysRequest{
+		CompartmentId: &compartmentId,
+	}
+	for {
+		response, err := kmsClient.ListKeys(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, response.Items...)
+		if response.OpcNextPage == nil {
+			return keys, nil
+		}
+		request.Page = response.OpcNextPage
+	}
+}
+
+// GetNodePool retrieves an OKE node pool given that node pool's Id.
+func (c *ClientImpl) GetNodePool(ctx context.Context, nodePoolId string) (*containerengine.NodePool, error) {
+	response, err := c.containerEngineClient.GetNodePool(ctx, containerengine.GetNodePoolRequest{
+		NodePoolId: &nodePoolId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response.NodePool, nil
+}
+
+// UpdateNodePoolTags replaces the tags of an OKE node pool, and of the nodes it launches
+func (c *ClientImpl) UpdateNodePoolTags(ctx context.Context, nodePoolId string, freeformTags map[string]string, definedTags map[string]map[string]interface{}) error {
+	_, err := c.containerEngineClient.UpdateNodePool(ctx, containerengine.UpdateNodePoolRequest{
+		NodePoolId: &nodePoolId,
+		UpdateNodePoolDetails: containerengine.UpdateNodePoolDetails{
+			FreeformTags: freeformTags,
+			DefinedTags:  definedTags,
+			NodeConfigDetails: &containerengine.UpdateNodePoolNodeConfigDetails{
+				FreeformTags: freeformTags,
+				DefinedTags:  definedTags,
+			},
+		},
+	})
+	return err
+}
+
 // GetSubnetById retrieves a subnet given that subnet's Id.
 func (c *ClientImpl) GetSubnetById(ctx context.Context, subnetId string) (*core.Subnet, error) {
 	response, err := c.vnClient.GetSubnet(ctx, core.GetSubnetRequest{
@@ -5,6 +5,7 @@ package pkg
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"github.com/rancher/kontainer-engine/types"
@@ -12,6 +13,7 @@ import (
 	driverconst "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/constants"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/k8s"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/snapshot"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/version"
 	"go.uber.org/zap"
@@ -20,12 +22,19 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"strings"
 	"time"
 )
 
 const (
 	metadataKey = "state"
+	planKey     = "plan"
+	// preemptibleNodePoolsKey lists the cluster's preemptible node pools, separated by commas
+	preemptibleNodePoolsKey = "preemptibleNodePools"
+	// apiServerProxyKey is the proxy URL used to reach the cluster's API server, if any
+	apiServerProxyKey = "apiServerProxy"
 )
 
 type OKEDriver struct {
@@ -44,6 +53,7 @@ func NewDriver() types.Driver {
 	driver.driverCapabilities.AddCapability(types.SetVersionCapability)
 	driver.driverCapabilities.AddCapability(types.GetClusterSizeCapability)
 	driver.driverCapabilities.AddCapability(types.SetClusterSizeCapability)
+	driver.driverCapabilities.AddCapability(types.EtcdBackupCapability)
 
 	return driver
 }
@@ -111,6 +121,28 @@ func (d *OKEDriver) GetDriverCreateOptions(ctx context.Context) (*types.DriverFl
 			DefaultBool: false,
 		},
 	}
+	driverFlag.Options[driverconst.VCNCIDR] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The CIDR block of a quick create VCN, from /16 to /22. The VCN's subnets are planned from this block, and it must not overlap the Pod or Cluster CIDR",
+		Default: &types.Default{
+			DefaultString: variables.DefaultVCNCIDR,
+		},
+	}
+	driverFlag.Options[driverconst.PrivateEndpoint] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Create the Kubernetes API endpoint in a private subnet without a public IP. Quick create VCN access to the endpoint is limited to the VCN",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
+	driverFlag.Options[driverconst.APIServerEndpoint] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The address the driver uses to reach a private Kubernetes API endpoint, e.g. https://127.0.0.1:6443 for a bastion port forward. Defaults to the cluster's API endpoint. Rancher is always given the cluster's API endpoint",
+	}
+	driverFlag.Options[driverconst.APIServerProxy] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The http, https or socks5 proxy URL the driver uses to reach a private Kubernetes API endpoint",
+	}
 
 	driverFlag.Options[driverconst.CNIType] = &types.Flag{
 		Type:  types.StringType,
@@ -122,10 +154,7 @@ func (d *OKEDriver) GetDriverCreateOptions(ctx context.Context) (*types.DriverFl
 
 	driverFlag.Options[driverconst.KubernetesVersion] = &types.Flag{
 		Type:  types.StringType,
-		Usage: "The Kubernetes version that will be used for your master and worker nodes e.g. v1.25.4, v1.26.2",
-		Default: &types.Default{
-			DefaultString: "v1.25.4",
-		},
+		Usage: "The Kubernetes version that will be used for your master and worker nodes e.g. v1.25.4, v1.26.2. Defaults to the latest version supported by OKE",
 	}
 
 	driverFlag.Options[driverconst.NodePublicKeyContents] = &types.Flag{
@@ -145,6 +174,10 @@ func (d *OKEDriver) GetDriverCreateOptions(ctx context.Context) (*types.DriverFl
 		Type:  types.StringType,
 		Usage: "The cloud provider region",
 	}
+	driverFlag.Options[driverconst.AuthType] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The OCI authentication type, one of UserPrincipal, InstancePrincipal or WorkloadIdentity. Defaults to the cloud credential's authentication type, or UserPrincipal",
+	}
 	driverFlag.Options[driverconst.CompartmentID] = &types.Flag{
 		Type:  types.StringType,
 		Usage: "The OCID of the compartment in which to create resources (VCN, worker nodes, etc.)",
@@ -158,6 +191,31 @@ func (d *OKEDriver) GetDriverCreateOptions(ctx context.Context) (*types.DriverFl
 		Type:  types.StringType,
 		Usage: "OCID for the node image (Optional)",
 	}
+	driverFlag.Options[driverconst.KMSKeyID] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The OCID of an AES KMS key in the compartment that encrypts the cluster's Kubernetes secrets (Optional)",
+	}
+	driverFlag.Options[driverconst.NodePVTransitEncryption] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Encrypt the traffic between nodes and their boot and block volumes",
+		Default: &types.Default{
+			DefaultBool: variables.DefaultNodePVTransitEncryption,
+		},
+	}
+	driverFlag.Options[driverconst.ImageVerification] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Only run images signed by one of the image verification KMS keys",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
+	driverFlag.Options[driverconst.ImageVerificationKMSKeyIDs] = &types.Flag{
+		Type:  types.StringSliceType,
+		Usage: "The OCIDs of the RSA KMS keys in the compartment that images are signed with",
+		Default: &types.Default{
+			DefaultStringSlice: &types.StringSlice{Value: []string{}}, // avoid nil value for init
+		},
+	}
 	driverFlag.Options[driverconst.WorkerNodeSubnet] = &types.Flag{
 		Type:  types.StringType,
 		Usage: "OCID for node pool subnet",
@@ -182,6 +240,63 @@ func (d *OKEDriver) GetDriverCreateOptions(ctx context.Context) (*types.DriverFl
 			DefaultStringSlice: &types.StringSlice{Value: []string{}}, // avoid nil value for init
 		},
 	}
+	driverFlag.Options[driverconst.NodePoolDistribution] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "How nodes are distributed across node pools when the cluster is resized, one of proportional, first-pool or even",
+		Default: &types.Default{
+			DefaultString: variables.DistributionProportional,
+		},
+	}
+	driverFlag.Options[driverconst.ClusterAutoscalerImage] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The cluster autoscaler image deployed for clusters with autoscaled node pools",
+		Default: &types.Default{
+			DefaultString: variables.DefaultClusterAutoscalerImage,
+		},
+	}
+	driverFlag.Options[driverconst.NodeCyclingMaxSurge] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The default number of nodes, e.g. 1, or percentage of a node pool's nodes, e.g. 25%, added while the pool's nodes are cycled. Node pools may set their own maxSurge",
+		Default: &types.Default{
+			DefaultString: variables.DefaultNodeCyclingMaxSurge,
+		},
+	}
+	driverFlag.Options[driverconst.NodeCyclingMaxUnavailable] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The default number of nodes, e.g. 0, or percentage of a node pool's nodes, e.g. 10%, unavailable while the pool's nodes are cycled. Node pools may set their own maxUnavailable",
+		Default: &types.Default{
+			DefaultString: variables.DefaultNodeCyclingMaxUnavailable,
+		},
+	}
+	driverFlag.Options[driverconst.NodeEvictionGraceDuration] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The default time nodes are drained before they are deleted, as an ISO 8601 duration up to PT60M. Node pools may set their own evictionGraceDuration",
+		Default: &types.Default{
+			DefaultString: variables.DefaultNodeEvictionGraceDuration,
+		},
+	}
+	driverFlag.Options[driverconst.NodeEvictionForceDelete] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Delete nodes that aren't drained after the eviction grace duration by default. Node pools may set their own forceDeleteAfterGraceDuration",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
+	driverFlag.Options[driverconst.RawAddons] = &types.Flag{
+		Type:  types.StringSliceType,
+		Usage: "OKE cluster add-ons, as JSON with a name, optional version and configurations. Add-ons that are removed are disabled",
+		Default: &types.Default{
+			DefaultStringSlice: &types.StringSlice{Value: []string{}}, // avoid nil value for init
+		},
+	}
+	driverFlag.Options[driverconst.RawFreeformTags] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "Freeform tags on every OCI resource of the cluster, as a JSON object of keys and values (Optional)",
+	}
+	driverFlag.Options[driverconst.RawDefinedTags] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "Defined tags on every OCI resource of the cluster, as a JSON object of tag namespaces to keys and values (Optional)",
+	}
 	driverFlag.Options[driverconst.ApplyYAMLs] = &types.Flag{
 		Type:  types.StringSliceType,
 		Usage: "YAMLs to apply on managed cluster",
@@ -233,6 +348,32 @@ func (d *OKEDriver) GetDriverCreateOptions(ctx context.Context) (*types.DriverFl
 		Type:  types.StringType,
 		Usage: "Private Registry URL",
 	}
+	driverFlag.Options[driverconst.SnapshotBackend] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The backend used to store cluster snapshots, objectstorage or filesystem. Defaults to objectstorage if a snapshot bucket is set. Snapshots fail if neither a backend nor a bucket is set",
+	}
+	driverFlag.Options[driverconst.SnapshotBucket] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The OCI Object Storage bucket used to store cluster snapshots",
+	}
+	driverFlag.Options[driverconst.SnapshotNamespace] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The OCI Object Storage namespace of the snapshot bucket (Optional)",
+	}
+	driverFlag.Options[driverconst.SnapshotDirectory] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The local directory used to store cluster snapshots when using the filesystem backend. The directory must be a persistent volume mounted in the driver's pod, or snapshots are lost when the pod restarts",
+		Default: &types.Default{
+			DefaultString: variables.DefaultSnapshotDirectory,
+		},
+	}
+	driverFlag.Options[driverconst.SnapshotSecrets] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Include Secrets in cluster snapshots. Snapshots are not encrypted, so Secrets are stored in plaintext in the snapshot backend",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
 	d.Logger.Infof("capi.driver.GetDriverUpdateOptions(...) called returning driver flags %v", driverFlag)
 	return &driverFlag, nil
 }
@@ -254,11 +395,65 @@ func (d *OKEDriver) GetDriverUpdateOptions(ctx context.Context) (*types.DriverFl
 		Type:  types.StringType,
 		Usage: "Image for cluster nodes",
 	}
+	driverFlag.Options[driverconst.NodePVTransitEncryption] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Encrypt the traffic between nodes and their boot and block volumes",
+		Default: &types.Default{
+			DefaultBool: variables.DefaultNodePVTransitEncryption,
+		},
+	}
+	driverFlag.Options[driverconst.ImageVerification] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Only run images signed by one of the image verification KMS keys",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
+	driverFlag.Options[driverconst.ImageVerificationKMSKeyIDs] = &types.Flag{
+		Type:  types.StringSliceType,
+		Usage: "The OCIDs of the RSA KMS keys in the compartment that images are signed with",
+		Default: &types.Default{
+			DefaultStringSlice: &types.StringSlice{Value: []string{}}, // avoid nil value for init
+		},
+	}
+	driverFlag.Options[driverconst.AuthType] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The OCI authentication type, one of UserPrincipal, InstancePrincipal or WorkloadIdentity. Defaults to the cloud credential's authentication type, or UserPrincipal",
+	}
+	driverFlag.Options[driverconst.PlanUpdate] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Plan the update and write the planned changes to the provisioning log, without applying them",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
+	driverFlag.Options[driverconst.PauseNodePoolRollout] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Pause upgrading node pools one at a time. Node pools the rollout has not upgraded stay at their current Kubernetes version until the rollout is resumed",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
+	driverFlag.Options[driverconst.NodePoolRolloutTimeout] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "How long to wait for each node pool to be upgraded during a rollout, e.g. 45m",
+		Default: &types.Default{
+			DefaultString: "30m",
+		},
+	}
+	driverFlag.Options[driverconst.APIServerEndpoint] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The address the driver uses to reach a private Kubernetes API endpoint, e.g. https://127.0.0.1:6443 for a bastion port forward. Defaults to the cluster's API endpoint. Rancher is always given the cluster's API endpoint",
+	}
+	driverFlag.Options[driverconst.APIServerProxy] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The http, https or socks5 proxy URL the driver uses to reach a private Kubernetes API endpoint",
+	}
 	driverFlag.Options[driverconst.KubernetesVersion] = &types.Flag{
 		Type:  types.StringType,
-		Usage: "The Kubernetes version that will be used for your master and worker nodes e.g. v1.11.9, v1.12.7",
+		Usage: "The Kubernetes version that will be used for your master and worker nodes e.g. v1.25.4, v1.26.2. Upgrades must not skip a minor version. Defaults to the cluster's current version",
 		Default: &types.Default{
-			DefaultString: "v1.25.4",
+			DefaultString: "",
 		},
 	}
 	driverFlag.Options[driverconst.NodePublicKeyContents] = &types.Flag{
@@ -272,6 +467,63 @@ func (d *OKEDriver) GetDriverUpdateOptions(ctx context.Context) (*types.DriverFl
 			DefaultStringSlice: &types.StringSlice{Value: []string{}}, // avoid nil value for init
 		},
 	}
+	driverFlag.Options[driverconst.NodePoolDistribution] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "How nodes are distributed across node pools when the cluster is resized, one of proportional, first-pool or even",
+		Default: &types.Default{
+			DefaultString: variables.DistributionProportional,
+		},
+	}
+	driverFlag.Options[driverconst.ClusterAutoscalerImage] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The cluster autoscaler image deployed for clusters with autoscaled node pools",
+		Default: &types.Default{
+			DefaultString: variables.DefaultClusterAutoscalerImage,
+		},
+	}
+	driverFlag.Options[driverconst.NodeCyclingMaxSurge] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The default number of nodes, e.g. 1, or percentage of a node pool's nodes, e.g. 25%, added while the pool's nodes are cycled. Node pools may set their own maxSurge",
+		Default: &types.Default{
+			DefaultString: variables.DefaultNodeCyclingMaxSurge,
+		},
+	}
+	driverFlag.Options[driverconst.NodeCyclingMaxUnavailable] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The default number of nodes, e.g. 0, or percentage of a node pool's nodes, e.g. 10%, unavailable while the pool's nodes are cycled. Node pools may set their own maxUnavailable",
+		Default: &types.Default{
+			DefaultString: variables.DefaultNodeCyclingMaxUnavailable,
+		},
+	}
+	driverFlag.Options[driverconst.NodeEvictionGraceDuration] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The default time nodes are drained before they are deleted, as an ISO 8601 duration up to PT60M. Node pools may set their own evictionGraceDuration",
+		Default: &types.Default{
+			DefaultString: variables.DefaultNodeEvictionGraceDuration,
+		},
+	}
+	driverFlag.Options[driverconst.NodeEvictionForceDelete] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Delete nodes that aren't drained after the eviction grace duration by default. Node pools may set their own forceDeleteAfterGraceDuration",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
+	driverFlag.Options[driverconst.RawAddons] = &types.Flag{
+		Type:  types.StringSliceType,
+		Usage: "OKE cluster add-ons, as JSON with a name, optional version and configurations. Add-ons that are removed are disabled",
+		Default: &types.Default{
+			DefaultStringSlice: &types.StringSlice{Value: []string{}}, // avoid nil value for init
+		},
+	}
+	driverFlag.Options[driverconst.RawFreeformTags] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "Freeform tags on every OCI resource of the cluster, as a JSON object of keys and values (Optional)",
+	}
+	driverFlag.Options[driverconst.RawDefinedTags] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "Defined tags on every OCI resource of the cluster, as a JSON object of tag namespaces to keys and values (Optional)",
+	}
 	driverFlag.Options[driverconst.ApplyYAMLs] = &types.Flag{
 		Type:  types.StringSliceType,
 		Usage: "YAMLs to apply on managed cluster",
@@ -316,6 +568,32 @@ func (d *OKEDriver) GetDriverUpdateOptions(ctx context.Context) (*types.DriverFl
 		Type:  types.StringType,
 		Usage: "Private Registry URL",
 	}
+	driverFlag.Options[driverconst.SnapshotBackend] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The backend used to store cluster snapshots, objectstorage or filesystem. Defaults to objectstorage if a snapshot bucket is set. Snapshots fail if neither a backend nor a bucket is set",
+	}
+	driverFlag.Options[driverconst.SnapshotBucket] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The OCI Object Storage bucket used to store cluster snapshots",
+	}
+	driverFlag.Options[driverconst.SnapshotNamespace] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The OCI Object Storage namespace of the snapshot bucket (Optional)",
+	}
+	driverFlag.Options[driverconst.SnapshotDirectory] = &types.Flag{
+		Type:  types.StringType,
+		Usage: "The local directory used to store cluster snapshots when using the filesystem backend. The directory must be a persistent volume mounted in the driver's pod, or snapshots are lost when the pod restarts",
+		Default: &types.Default{
+			DefaultString: variables.DefaultSnapshotDirectory,
+		},
+	}
+	driverFlag.Options[driverconst.SnapshotSecrets] = &types.Flag{
+		Type:  types.BoolType,
+		Usage: "Include Secrets in cluster snapshots. Snapshots are not encrypted, so Secrets are stored in plaintext in the snapshot backend",
+		Default: &types.Default{
+			DefaultBool: false,
+		},
+	}
 	return &driverFlag, nil
 }
 
@@ -332,6 +610,10 @@ func (d *OKEDriver) Create(ctx context.Context, opts *types.DriverOptions, _ *ty
 		return nil, err
 	}
 	_ = plog.Infof("Initializing cluster")
+	if err := validate(vars, plog); err != nil {
+		d.Logger.Errorf("invalid cluster options %v", err)
+		return nil, err
+	}
 	/*
 	* The ClusterInfo includes the following information Version, ServiceAccountToken,Endpoint, username, password, etc
 	 */
@@ -365,9 +647,6 @@ func (d *OKEDriver) Update(ctx context.Context, info *types.ClusterInfo, opts *t
 	if err := state.SetUpdateValues(ctx, newState); err != nil {
 		return info, err
 	}
-	if err := storeVariables(info, state); err != nil {
-		return info, err
-	}
 	di, err := k8s.InjectedDynamic()
 	if err != nil {
 		return info, err
@@ -378,7 +657,23 @@ func (d *OKEDriver) Update(ctx context.Context, info *types.ClusterInfo, opts *t
 	}
 
 	plog := provisioning.NewLogger(ctx, ki, state.Name)
-	if err := d.NewCAPIClient(plog).UpdateCluster(ctx, ki, di, state); err != nil {
+	if err := validate(state, plog); err != nil {
+		return info, err
+	}
+	if state.PlanUpdate {
+		// plans are not stored as the cluster state, so the update can be applied later
+		return info, d.planUpdate(ctx, info, di, state, plog)
+	}
+	if err := storeVariables(info, state); err != nil {
+		return info, err
+	}
+	if err := d.NewCAPIClient(plog).UpdateCluster(ctx, ki, di, managedInterface(state), state); err != nil {
+		return info, err
+	}
+	if err := tagNodePools(ctx, ki, di, state, plog); err != nil {
+		return info, err
+	}
+	if err := storeVariables(info, state); err != nil {
 		return info, err
 	}
 
@@ -404,10 +699,28 @@ func (d *OKEDriver) PostCheck(ctx context.Context, info *types.ClusterInfo) (*ty
 	if err := capi.IsCAPIClusterReady(ctx, adminDi, state, plog); err != nil {
 		return info, err
 	}
+	if err := tagNodePools(ctx, adminKi, adminDi, state, plog); err != nil {
+		return info, err
+	}
+	if err := storeVariables(info, state); err != nil {
+		return info, err
+	}
 	capiClusterKubeConfig, err := state.GetCAPIClusterKubeConfig(ctx)
 	if err != nil {
 		return info, err
 	}
+	originalKubeConfigBytes, err := yaml.Marshal(&capiClusterKubeConfig)
+	if err != nil {
+		return info, fmt.Errorf("failed to get managed cluster kubeconfig: %v", err)
+	}
+	kubeConfigBytes, err := k8s.SetAPIServer(originalKubeConfigBytes, state.APIServerEndpoint, state.APIServerProxy)
+	if err != nil {
+		return info, err
+	}
+	apiServer, err := k8s.RancherAPIServer(originalKubeConfigBytes, kubeConfigBytes)
+	if err != nil {
+		return info, fmt.Errorf("failed to get managed cluster API server: %v", err)
+	}
 
 	nc, err := state.NodeCount()
 	if err != nil {
@@ -421,10 +734,14 @@ func (d *OKEDriver) PostCheck(ctx context.Context, info *types.ClusterInfo) (*ty
 	info.ClientKey = ""
 	info.NodeCount = nc.Count
 	info.Metadata["nodePool"] = state.Name + "-1"
-	if len(capiClusterKubeConfig.Clusters) > 0 {
-		capiCluster := capiClusterKubeConfig.Clusters[0].Cluster
-		info.Endpoint = capiCluster.Server
-		info.RootCaCertificate = capiCluster.CertificateAuthorityData
+	info.Metadata[preemptibleNodePoolsKey] = strings.Join(state.PreemptibleNodePools(), ",")
+	// Rancher reaches the cluster's own API endpoint, through any proxy override
+	info.Endpoint = apiServer.Server
+	info.RootCaCertificate = base64.StdEncoding.EncodeToString(apiServer.CertificateAuthorityData)
+	if apiServer.ProxyURL != "" {
+		info.Metadata[apiServerProxyKey] = apiServer.ProxyURL
+	} else {
+		delete(info.Metadata, apiServerProxyKey)
 	}
 
 	// Use as a temporary token while we generate a service account.
@@ -436,11 +753,6 @@ func (d *OKEDriver) PostCheck(ctx context.Context, info *types.ClusterInfo) (*ty
 		// https://github.com/rancher/rancher/issues/24135
 	}
 
-	kubeConfigBytes, err := yaml.Marshal(&capiClusterKubeConfig)
-	if err != nil {
-		return info, fmt.Errorf("failed to get managed cluster kubeconfig: %v", err)
-	}
-
 	managedKI, err := k8s.NewInterfaceForKubeconfig(kubeConfigBytes)
 	if err != nil {
 		return info, fmt.Errorf("failed to create clientset for managed cluster %s: %v", state.Name, err)
@@ -515,9 +827,22 @@ func (d *OKEDriver) SetClusterSize(ctx context.Context, info *types.ClusterInfo,
 		return err
 	}
 
-	if len(state.NodePools) > 0 {
-		state.NodePools[0].Replicas = count.Count
+	plog, err := newProvisioningLogger(ctx, state.Name)
+	if err != nil {
+		return err
+	}
+	replicas, err := variables.DistributeNodeCount(state.NodePools, count.Count, state.NodePoolDistribution)
+	if err != nil {
+		_ = plog.Errorf("Failed to resize cluster: %v", err)
+		return err
+	}
+	if err := state.SetNodePoolReplicas(replicas); err != nil {
+		return err
+	}
+	if err := validate(state, plog); err != nil {
+		return err
 	}
+	_ = plog.Infof("Resizing cluster to %d nodes: %s", count.Count, variables.DescribeNodePoolReplicas(state.NodePools, replicas))
 	if err := storeVariables(info, state); err != nil {
 		d.Logger.Errorf("Failed to save new node group size: %v", err)
 		return err
@@ -525,6 +850,25 @@ func (d *OKEDriver) SetClusterSize(ctx context.Context, info *types.ClusterInfo,
 	return d.doCreateOrUpdate(ctx, state)
 }
 
+// planUpdate writes the changes an update would make to the provisioning log and the cluster metadata
+func (d *OKEDriver) planUpdate(ctx context.Context, info *types.ClusterInfo, di dynamic.Interface, state *variables.Variables, plog *provisioning.Logger) error {
+	plan, err := d.NewCAPIClient(plog).PlanUpdate(ctx, di, state)
+	if err != nil {
+		_ = plog.Errorf("Failed to plan update: %v", err)
+		return err
+	}
+	_ = plog.Infof("Planned update, no changes were applied:\n%s", plan)
+	planBytes, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+	if info.Metadata == nil {
+		info.Metadata = map[string]string{}
+	}
+	info.Metadata[planKey] = string(planBytes)
+	return nil
+}
+
 // SetVersion sets the Kubernetes Version of cluster
 func (d *OKEDriver) SetVersion(ctx context.Context, info *types.ClusterInfo, version *types.KubernetesVersion) error {
 	d.Logger.Infof("capi.driver.SetVersion(...) called")
@@ -540,8 +884,18 @@ func (d *OKEDriver) SetVersion(ctx context.Context, info *types.ClusterInfo, ver
 	if err != nil {
 		return err
 	}
+	plog := provisioning.NewLogger(ctx, ki, state.Name)
+	currentVersion := state.KubernetesVersion
+	if err := state.SetKubernetesVersion(ctx, version.Version); err != nil {
+		_ = plog.Errorf("Failed to set Kubernetes version: %v", err)
+		return err
+	}
+	if err := storeVariables(info, state); err != nil {
+		return err
+	}
+	_ = plog.Infof("Upgrading Kubernetes version from %s to %s", currentVersion, state.KubernetesVersion)
 
-	return d.NewCAPIClient(provisioning.NewLogger(ctx, ki, state.Name)).UpdateCluster(ctx, ki, di, state)
+	return d.NewCAPIClient(plog).UpdateCluster(ctx, ki, di, managedInterface(state), state)
 }
 
 func (d *OKEDriver) GetCapabilities(_ context.Context) (*types.Capabilities, error) {
@@ -549,19 +903,38 @@ func (d *OKEDriver) GetCapabilities(_ context.Context) (*types.Capabilities, err
 	return &d.driverCapabilities, nil
 }
 
-func (d *OKEDriver) ETCDSave(ctx context.Context, clusterInfo *types.ClusterInfo, opts *types.DriverOptions, snapshotName string) error {
+// ETCDSave saves a snapshot of the managed cluster's Kubernetes objects. OKE does not expose etcd.
+func (d *OKEDriver) ETCDSave(ctx context.Context, clusterInfo *types.ClusterInfo, _ *types.DriverOptions, snapshotName string) error {
 	d.Logger.Infof("capi.driver.ETCDSave(...) called")
-	return fmt.Errorf("ETCD backup operations are not implemented")
+	snapshotter, managedDi, err := d.newSnapshotter(ctx, clusterInfo)
+	if err != nil {
+		return err
+	}
+	_, err = snapshotter.Save(ctx, managedDi, snapshotName)
+	return err
 }
 
-func (d *OKEDriver) ETCDRestore(ctx context.Context, clusterInfo *types.ClusterInfo, opts *types.DriverOptions, snapshotName string) (*types.ClusterInfo, error) {
+// ETCDRestore restores the managed cluster's Kubernetes objects from a snapshot
+func (d *OKEDriver) ETCDRestore(ctx context.Context, clusterInfo *types.ClusterInfo, _ *types.DriverOptions, snapshotName string) (*types.ClusterInfo, error) {
 	d.Logger.Infof("capi.driver.ETCDRestore(...) called")
-	return nil, fmt.Errorf("ETCD backup operations are not implemented")
+	snapshotter, managedDi, err := d.newSnapshotter(ctx, clusterInfo)
+	if err != nil {
+		return clusterInfo, err
+	}
+	if _, err := snapshotter.Restore(ctx, managedDi, snapshotName); err != nil {
+		return clusterInfo, err
+	}
+	return clusterInfo, nil
 }
 
-func (d *OKEDriver) ETCDRemoveSnapshot(ctx context.Context, clusterInfo *types.ClusterInfo, opts *types.DriverOptions, snapshotName string) error {
+// ETCDRemoveSnapshot deletes a snapshot of the managed cluster
+func (d *OKEDriver) ETCDRemoveSnapshot(ctx context.Context, clusterInfo *types.ClusterInfo, _ *types.DriverOptions, snapshotName string) error {
 	d.Logger.Infof("capi.driver.ETCDRemoveSnapshot(...) called")
-	return fmt.Errorf("ETCD backup operations are not implemented")
+	snapshotter, _, err := d.newSnapshotter(ctx, clusterInfo)
+	if err != nil {
+		return err
+	}
+	return snapshotter.Remove(ctx, snapshotName)
 }
 
 func (d *OKEDriver) GetK8SCapabilities(ctx context.Context, options *types.DriverOptions) (*types.K8SCapabilities, error) {
@@ -714,6 +1087,16 @@ func (d *OKEDriver) doCreateOrUpdate(ctx context.Context, state *variables.Varia
 	return nil
 }
 
+// validate checks the cluster options before any objects are written, writing every problem to the provisioning log
+func validate(state *variables.Variables, plog *provisioning.Logger) error {
+	errs := state.Validate()
+	if len(errs) == 0 {
+		return nil
+	}
+	_ = plog.Errorf("Invalid cluster options:\n%s", variables.DescribeValidationErrors(errs))
+	return fmt.Errorf("invalid cluster options: %v", errs.ToAggregate())
+}
+
 func loadDefaults(ctx context.Context) (*version.Defaults, error) {
 	ki, err := k8s.InjectedInterface()
 	if err != nil {
@@ -730,6 +1113,70 @@ func (d *OKEDriver) NewCAPIClient(logger *provisioning.Logger) *capi.CAPIClient
 	return capi.NewCAPIClient(logger)
 }
 
+// newSnapshotter creates a snapshotter for the cluster, and a dynamic client for the managed cluster
+func (d *OKEDriver) newSnapshotter(ctx context.Context, info *types.ClusterInfo) (*snapshot.Snapshotter, dynamic.Interface, error) {
+	state, err := d.loadVariables(info)
+	if err != nil {
+		return nil, nil, err
+	}
+	adminKi, err := k8s.InjectedInterface()
+	if err != nil {
+		return nil, nil, err
+	}
+	// refresh OCI credentials for the snapshot backend
+	if err := variables.SetupOCIAuth(ctx, adminKi, state); err != nil {
+		return nil, nil, err
+	}
+	kubeConfigBytes, err := managedKubeConfig(ctx, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	managedDI, err := k8s.NewDynamicForKubeconfig(kubeConfigBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create dynamic clientset for managed cluster %s: %v", state.Name, err)
+	}
+	return snapshot.NewSnapshotter(adminKi, state, provisioning.NewLogger(ctx, adminKi, state.Name)), managedDI, nil
+}
+
+// tagNodePools sets the tags of node pools that override the cluster's tags, and removes the ones no longer configured, once the node pools exist
+func tagNodePools(ctx context.Context, ki kubernetes.Interface, di dynamic.Interface, state *variables.Variables, plog *provisioning.Logger) error {
+	if !state.HasNodePoolTags() {
+		return nil
+	}
+	if err := variables.SetupOCIAuth(ctx, ki, state); err != nil {
+		return err
+	}
+	client, err := variables.OCIClientGetter(state)
+	if err != nil {
+		return err
+	}
+	return capi.TagNodePools(ctx, di, client, state, plog)
+}
+
+// managedInterface creates a client for the managed cluster when it is needed
+func managedInterface(state *variables.Variables) capi.ManagedInterface {
+	return func(ctx context.Context) (kubernetes.Interface, error) {
+		kubeConfigBytes, err := managedKubeConfig(ctx, state)
+		if err != nil {
+			return nil, err
+		}
+		return k8s.NewInterfaceForKubeconfig(kubeConfigBytes)
+	}
+}
+
+// managedKubeConfig fetches the serialized kubeconfig of the managed cluster, using the driver's API server overrides
+func managedKubeConfig(ctx context.Context, state *variables.Variables) ([]byte, error) {
+	capiClusterKubeConfig, err := state.GetCAPIClusterKubeConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	kubeConfigBytes, err := yaml.Marshal(&capiClusterKubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed cluster kubeconfig: %v", err)
+	}
+	return k8s.SetAPIServer(kubeConfigBytes, state.APIServerEndpoint, state.APIServerProxy)
+}
+
 func newProvisioningLogger(ctx context.Context, name string) (*provisioning.Logger, error) {
 	ki, err := k8s.InjectedInterface()
 	if err != nil {
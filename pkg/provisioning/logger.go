@@ -28,6 +28,7 @@ type (
 
 const (
 	INFO  level = "[INFO]"
+	WARN  level = "[WARN]"
 	ERROR level = "[ERROR]"
 
 	// These names conventions are used by kontainer-engine for provisioning-log
@@ -128,6 +129,10 @@ func (l *Logger) Infof(format string, args ...any) error {
 	return l.write(INFO, fmt.Sprintf(format, args...))
 }
 
+func (l *Logger) Warnf(format string, args ...any) error {
+	return l.write(WARN, fmt.Sprintf(format, args...))
+}
+
 func (l *Logger) Errorf(format string, args ...any) error {
 	return l.write(ERROR, fmt.Sprintf(format, args...))
 }
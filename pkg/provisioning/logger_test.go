@@ -108,6 +108,8 @@ func TestLogger(t *testing.T) {
 	assert.NoError(t, err)
 	// write another message. It should be the last message
 	_ = assertLastMessage(t, ctx, ki, "hi from test cluster")
+	assert.NoError(t, log.Warnf(testMsg1))
+	_ = assertLastMessage(t, ctx, ki, string(WARN)+" "+testMsg1)
 	longMsg := makeLongTestString('a', maxLength)
 	err = log.Infof(longMsg)
 	assert.NoError(t, err)
@@ -0,0 +1,109 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package region
+
+import (
+	"fmt"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"strings"
+)
+
+// Region is the metadata of an OCI region
+type Region struct {
+	// Name is the region identifier, e.g. us-ashburn-1
+	Name string `json:"name"`
+	// Key is the region's short key, e.g. iad
+	Key string `json:"key"`
+	// Realm is the realm the region belongs to, e.g. oc1
+	Realm string `json:"realm"`
+	// Domain is the realm's second level domain, e.g. oraclecloud.com
+	Domain string `json:"domain"`
+}
+
+// ServiceCIDRLabel is the label of the region's Oracle Services Network service CIDR, used in security rules
+func (r Region) ServiceCIDRLabel() string {
+	return fmt.Sprintf("all-%s-services-in-oracle-services-network", r.Key)
+}
+
+// New creates the metadata of a region missing from the known regions, such as a region newer than the driver.
+// The realm and domain are resolved by the OCI SDK, and are empty if the SDK doesn't know the region either.
+func New(name, key string) *Region {
+	r := &Region{
+		Name: strings.ToLower(name),
+		Key:  strings.ToLower(key),
+	}
+	if realm, err := common.StringToRegion(r.Name).RealmID(); err == nil {
+		r.Realm = realm
+		// endpoints are <service>.<region>.<domain>
+		r.Domain = strings.TrimPrefix(common.StringToRegion(r.Name).Endpoint("service"), fmt.Sprintf("service.%s.", r.Name))
+	}
+	return r
+}
+
+// Lookup finds a region by name or key in the known regions
+func Lookup(name string) (*Region, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	for i := range regions {
+		if regions[i].Name == key || regions[i].Key == key {
+			r := regions[i]
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown OCI region %s", name)
+}
+
+// regions are the known OCI regions, by realm
+var regions = []Region{
+	{Name: "af-johannesburg-1", Key: "jnb", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-chuncheon-1", Key: "yny", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-hyderabad-1", Key: "hyd", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-melbourne-1", Key: "mel", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-mumbai-1", Key: "bom", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-osaka-1", Key: "kix", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-seoul-1", Key: "icn", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-singapore-1", Key: "sin", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-sydney-1", Key: "syd", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ap-tokyo-1", Key: "nrt", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ca-montreal-1", Key: "yul", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "ca-toronto-1", Key: "yyz", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "eu-amsterdam-1", Key: "ams", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "eu-frankfurt-1", Key: "fra", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "eu-madrid-1", Key: "mad", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "eu-marseille-1", Key: "mrs", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "eu-milan-1", Key: "lin", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "eu-paris-1", Key: "cdg", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "eu-stockholm-1", Key: "arn", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "eu-zurich-1", Key: "zrh", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "il-jerusalem-1", Key: "mtz", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "me-abudhabi-1", Key: "auh", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "me-dubai-1", Key: "dxb", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "me-jeddah-1", Key: "jed", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "mx-queretaro-1", Key: "qro", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "sa-santiago-1", Key: "scl", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "sa-saopaulo-1", Key: "gru", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "sa-vinhedo-1", Key: "vcp", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "uk-cardiff-1", Key: "cwl", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "uk-london-1", Key: "lhr", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "us-ashburn-1", Key: "iad", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "us-chicago-1", Key: "ord", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "us-phoenix-1", Key: "phx", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "us-sanjose-1", Key: "sjc", Realm: "oc1", Domain: "oraclecloud.com"},
+	{Name: "us-langley-1", Key: "lfi", Realm: "oc2", Domain: "oraclegovcloud.com"},
+	{Name: "us-luke-1", Key: "luf", Realm: "oc2", Domain: "oraclegovcloud.com"},
+	{Name: "us-gov-ashburn-1", Key: "ric", Realm: "oc3", Domain: "oraclegovcloud.com"},
+	{Name: "us-gov-chicago-1", Key: "pia", Realm: "oc3", Domain: "oraclegovcloud.com"},
+	{Name: "us-gov-phoenix-1", Key: "tus", Realm: "oc3", Domain: "oraclegovcloud.com"},
+	{Name: "uk-gov-cardiff-1", Key: "brs", Realm: "oc4", Domain: "oraclegovcloud.uk"},
+	{Name: "uk-gov-london-1", Key: "ltn", Realm: "oc4", Domain: "oraclegovcloud.uk"},
+	{Name: "ap-chiyoda-1", Key: "nja", Realm: "oc8", Domain: "oraclecloud8.com"},
+	{Name: "ap-ibaraki-1", Key: "ukb", Realm: "oc8", Domain: "oraclecloud8.com"},
+	{Name: "me-dcc-muscat-1", Key: "mct", Realm: "oc9", Domain: "oraclecloud9.com"},
+	{Name: "ap-dcc-canberra-1", Key: "wga", Realm: "oc10", Domain: "oraclecloud10.com"},
+	{Name: "eu-dcc-dublin-1", Key: "ork", Realm: "oc14", Domain: "oraclecloud14.com"},
+	{Name: "eu-dcc-dublin-2", Key: "snn", Realm: "oc14", Domain: "oraclecloud14.com"},
+	{Name: "eu-dcc-milan-1", Key: "bgy", Realm: "oc14", Domain: "oraclecloud14.com"},
+	{Name: "eu-dcc-milan-2", Key: "mxp", Realm: "oc14", Domain: "oraclecloud14.com"},
+	{Name: "eu-dcc-rating-1", Key: "dus", Realm: "oc14", Domain: "oraclecloud14.com"},
+	{Name: "eu-dcc-rating-2", Key: "dtm", Realm: "oc14", Domain: "oraclecloud14.com"},
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package region
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	var tests = []struct {
+		name     string
+		region   string
+		label    string
+		realm    string
+		hasError bool
+	}{
+		{
+			"Ashburn",
+			"us-ashburn-1",
+			"all-iad-services-in-oracle-services-network",
+			"oc1",
+			false,
+		},
+		{
+			"Frankfurt by key",
+			"FRA",
+			"all-fra-services-in-oracle-services-network",
+			"oc1",
+			false,
+		},
+		{
+			"government realm",
+			"us-gov-phoenix-1",
+			"all-tus-services-in-oracle-services-network",
+			"oc3",
+			false,
+		},
+		{
+			"unknown region",
+			"us-nowhere-1",
+			"",
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Lookup(tt.region)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.label, r.ServiceCIDRLabel())
+			assert.Equal(t, tt.realm, r.Realm)
+		})
+	}
+}
+
+func TestRegionsAreUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, r := range regions {
+		assert.False(t, seen[r.Name], r.Name)
+		assert.False(t, seen[r.Key], r.Key)
+		seen[r.Name] = true
+		seen[r.Key] = true
+	}
+}
+
+func TestNew(t *testing.T) {
+	r := New("US-ASHBURN-1", "IAD")
+	assert.Equal(t, Region{Name: "us-ashburn-1", Key: "iad", Realm: "oc1", Domain: "oraclecloud.com"}, *r)
+	assert.Equal(t, "all-iad-services-in-oracle-services-network", r.ServiceCIDRLabel())
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	driverconst "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/constants"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	"os"
+	"path/filepath"
+)
+
+const (
+	BackendObjectStorage = variables.SnapshotBackendObjectStorage
+	BackendFilesystem    = variables.SnapshotBackendFilesystem
+)
+
+// Backend is an object store for snapshot archives
+type Backend interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	// Config is the resolved configuration of the backend, recorded with each snapshot
+	Config() BackendConfig
+	// Location is a human-readable location of a key in the backend
+	Location(key string) string
+}
+
+// BackendConfig identifies where snapshot archives are stored
+type BackendConfig struct {
+	Type      string `json:"type"`
+	Bucket    string `json:"bucket,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Directory string `json:"directory,omitempty"`
+}
+
+var ObjectStorageGetter = func(v *variables.Variables) (oci.ObjectStorage, error) {
+	provider, err := v.GetConfigurationProvider()
+	if err != nil {
+		return nil, err
+	}
+	return oci.NewObjectStorage(provider)
+}
+
+// ConfigFromVariables creates a BackendConfig from the cluster's snapshot settings.
+// The filesystem backend is only used if the cluster opts in, as the driver's local storage doesn't outlive the driver's pod.
+func ConfigFromVariables(v *variables.Variables) BackendConfig {
+	cfg := BackendConfig{
+		Type:      v.SnapshotBackend,
+		Bucket:    v.SnapshotBucket,
+		Namespace: v.SnapshotNamespace,
+		Directory: v.SnapshotDirectory,
+	}
+	if cfg.Type == "" && cfg.Bucket != "" {
+		cfg.Type = BackendObjectStorage
+	}
+	if cfg.Type == BackendFilesystem && cfg.Directory == "" {
+		cfg.Directory = variables.DefaultSnapshotDirectory
+	}
+	return cfg
+}
+
+// NewBackend creates the Backend described by cfg
+func NewBackend(ctx context.Context, cfg BackendConfig, v *variables.Variables) (Backend, error) {
+	switch cfg.Type {
+	case "":
+		return nil, fmt.Errorf("snapshots require a %s, or the %s backend with a %s on a persistent volume", driverconst.SnapshotBucket, BackendFilesystem, driverconst.SnapshotDirectory)
+	case BackendFilesystem:
+		return &filesystemBackend{
+			directory: cfg.Directory,
+		}, nil
+	case BackendObjectStorage:
+		if cfg.Bucket == "" {
+			return nil, errors.New("a snapshot bucket is required for the objectstorage snapshot backend")
+		}
+		client, err := ObjectStorageGetter(v)
+		if err != nil {
+			return nil, err
+		}
+		// default to the tenancy's Object Storage namespace
+		if cfg.Namespace == "" {
+			cfg.Namespace, err = client.GetNamespace(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get Object Storage namespace: %v", err)
+			}
+		}
+		return &objectStorageBackend{
+			client:    client,
+			namespace: cfg.Namespace,
+			bucket:    cfg.Bucket,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown snapshot backend %s", cfg.Type)
+	}
+}
+
+type objectStorageBackend struct {
+	client    oci.ObjectStorage
+	namespace string
+	bucket    string
+}
+
+func (o *objectStorageBackend) Put(ctx context.Context, key string, data []byte) error {
+	return o.client.PutObject(ctx, o.namespace, o.bucket, key, data)
+}
+
+func (o *objectStorageBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	return o.client.GetObject(ctx, o.namespace, o.bucket, key)
+}
+
+func (o *objectStorageBackend) Delete(ctx context.Context, key string) error {
+	return o.client.DeleteObject(ctx, o.namespace, o.bucket, key)
+}
+
+func (o *objectStorageBackend) Config() BackendConfig {
+	return BackendConfig{
+		Type:      BackendObjectStorage,
+		Bucket:    o.bucket,
+		Namespace: o.namespace,
+	}
+}
+
+func (o *objectStorageBackend) Location(key string) string {
+	return fmt.Sprintf("oci://%s@%s/%s", o.bucket, o.namespace, key)
+}
+
+type filesystemBackend struct {
+	directory string
+}
+
+func (f *filesystemBackend) Put(_ context.Context, key string, data []byte) error {
+	path := f.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func (f *filesystemBackend) Get(_ context.Context, key string) ([]byte, error) {
+	return os.ReadFile(f.path(key))
+}
+
+func (f *filesystemBackend) Delete(_ context.Context, key string) error {
+	err := os.Remove(f.path(key))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (f *filesystemBackend) Config() BackendConfig {
+	return BackendConfig{
+		Type:      BackendFilesystem,
+		Directory: f.directory,
+	}
+}
+
+func (f *filesystemBackend) Location(key string) string {
+	return "file://" + f.path(key)
+}
+
+func (f *filesystemBackend) path(key string) string {
+	return filepath.Join(f.directory, filepath.FromSlash(key))
+}
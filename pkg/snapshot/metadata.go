@@ -0,0 +1,104 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// metadataConfigMapName is the admin cluster ConfigMap in the cluster namespace that indexes the cluster's snapshots
+	metadataConfigMapName = "snapshots"
+)
+
+// Metadata describes a saved snapshot
+type Metadata struct {
+	Name     string        `json:"name"`
+	Cluster  string        `json:"cluster"`
+	Key      string        `json:"key"`
+	Location string        `json:"location"`
+	Backend  BackendConfig `json:"backend"`
+	Objects  int           `json:"objects"`
+	Created  string        `json:"created"`
+}
+
+func getMetadata(ctx context.Context, ki kubernetes.Interface, namespace, name string) (*Metadata, error) {
+	cm, err := ki.CoreV1().ConfigMaps(namespace).Get(ctx, metadataConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := cm.Data[name]
+	if !ok {
+		return nil, nil
+	}
+	m := &Metadata{}
+	if err := json.Unmarshal([]byte(raw), m); err != nil {
+		return nil, fmt.Errorf("invalid metadata for snapshot %s: %v", name, err)
+	}
+	return m, nil
+}
+
+// putMetadata records a snapshot in the cluster's snapshot index. The index is shared by the cluster's snapshots, so conflicting changes are retried.
+func putMetadata(ctx context.Context, ki kubernetes.Interface, namespace string, m *Metadata) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := ki.CoreV1().ConfigMaps(namespace).Get(ctx, metadataConfigMapName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err := ki.CoreV1().ConfigMaps(namespace).Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metadataConfigMapName,
+					Namespace: namespace,
+				},
+				Data: map[string]string{
+					m.Name: string(raw),
+				},
+			}, metav1.CreateOptions{})
+			// another snapshot created the index first, so retry with an update
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), metadataConfigMapName, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[m.Name] = string(raw)
+		_, err = ki.CoreV1().ConfigMaps(namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func deleteMetadata(ctx context.Context, ki kubernetes.Interface, namespace, name string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := ki.CoreV1().ConfigMaps(namespace).Get(ctx, metadataConfigMapName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := cm.Data[name]; !ok {
+			return nil
+		}
+		delete(cm.Data, name)
+		_, err = ki.CoreV1().ConfigMaps(namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
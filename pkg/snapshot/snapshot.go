@@ -0,0 +1,328 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAccountTokenType = "kubernetes.io/service-account-token"
+	rbacBootstrappingLabel  = "kubernetes.io/bootstrapping"
+)
+
+// Resources are the managed cluster resources captured by a snapshot, in the order they are restored
+var Resources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "namespaces"},
+	{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"},
+	{Version: "v1", Resource: "serviceaccounts"},
+	{Version: "v1", Resource: "secrets"},
+	{Version: "v1", Resource: "configmaps"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+	{Version: "v1", Resource: "persistentvolumeclaims"},
+	{Version: "v1", Resource: "services"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "batch", Version: "v1", Resource: "cronjobs"},
+}
+
+// systemNamespaces are owned by OKE, and are not captured by snapshots
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+var validSnapshotName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// archive is the serialized form of a snapshot
+type archive struct {
+	Name      string            `json:"name"`
+	Cluster   string            `json:"cluster"`
+	Created   string            `json:"created"`
+	Resources []resourceObjects `json:"resources"`
+}
+
+type resourceObjects struct {
+	Group    string                      `json:"group,omitempty"`
+	Version  string                      `json:"version"`
+	Resource string                      `json:"resource"`
+	Objects  []unstructured.Unstructured `json:"objects"`
+}
+
+func (r resourceObjects) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    r.Group,
+		Version:  r.Version,
+		Resource: r.Resource,
+	}
+}
+
+// Snapshotter saves and restores the Kubernetes objects of a managed cluster.
+// OKE does not expose etcd, so snapshots are taken through the managed cluster's API server.
+type Snapshotter struct {
+	// admin cluster client, used to record snapshot metadata in the cluster namespace
+	ki   kubernetes.Interface
+	v    *variables.Variables
+	plog *provisioning.Logger
+}
+
+func NewSnapshotter(ki kubernetes.Interface, v *variables.Variables, plog *provisioning.Logger) *Snapshotter {
+	return &Snapshotter{
+		ki:   ki,
+		v:    v,
+		plog: plog,
+	}
+}
+
+// Save captures the managed cluster's objects in the snapshot backend, and records the snapshot metadata
+func (s *Snapshotter) Save(ctx context.Context, managedDi dynamic.Interface, name string) (*Metadata, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	backend, err := NewBackend(ctx, ConfigFromVariables(s.v), s.v)
+	if err != nil {
+		return nil, err
+	}
+	a := &archive{
+		Name:    name,
+		Cluster: s.v.Name,
+		Created: time.Now().UTC().Format(time.RFC3339),
+	}
+	count := 0
+	for _, gvr := range s.resources() {
+		objects, err := listRestorable(ctx, managedDi, gvr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", gvr.Resource, err)
+		}
+		count += len(objects)
+		a.Resources = append(a.Resources, resourceObjects{
+			Group:    gvr.Group,
+			Version:  gvr.Version,
+			Resource: gvr.Resource,
+			Objects:  objects,
+		})
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize snapshot %s: %v", name, err)
+	}
+	key := s.key(name)
+	if err := backend.Put(ctx, key, data); err != nil {
+		return nil, fmt.Errorf("failed to store snapshot %s: %v", name, err)
+	}
+	m := &Metadata{
+		Name:     name,
+		Cluster:  s.v.Name,
+		Key:      key,
+		Location: backend.Location(key),
+		Backend:  backend.Config(),
+		Objects:  count,
+		Created:  a.Created,
+	}
+	if err := putMetadata(ctx, s.ki, s.v.Namespace, m); err != nil {
+		return nil, fmt.Errorf("failed to record metadata for snapshot %s: %v", name, err)
+	}
+	_ = s.plog.Infof("Saved snapshot %s with %d objects to %s", name, count, m.Location)
+	return m, nil
+}
+
+// Restore creates or updates the managed cluster's objects from a saved snapshot
+func (s *Snapshotter) Restore(ctx context.Context, managedDi dynamic.Interface, name string) (*Metadata, error) {
+	m, backend, err := s.lookup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := backend.Get(ctx, m.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot %s: %v", name, err)
+	}
+	a := &archive{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("failed to deserialize snapshot %s: %v", name, err)
+	}
+	_ = s.plog.Infof("Restoring snapshot %s", name)
+	for _, r := range a.Resources {
+		for idx := range r.Objects {
+			if err := restoreObject(ctx, managedDi, r.GVR(), &r.Objects[idx]); err != nil {
+				_ = s.plog.Errorf("Failed to restore snapshot %s", name)
+				return nil, err
+			}
+		}
+	}
+	_ = s.plog.Infof("Restored snapshot %s", name)
+	return m, nil
+}
+
+// Remove deletes a saved snapshot and its metadata
+func (s *Snapshotter) Remove(ctx context.Context, name string) error {
+	m, backend, err := s.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := backend.Delete(ctx, m.Key); err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %v", name, err)
+	}
+	if err := deleteMetadata(ctx, s.ki, s.v.Namespace, name); err != nil {
+		return fmt.Errorf("failed to delete metadata for snapshot %s: %v", name, err)
+	}
+	_ = s.plog.Infof("Removed snapshot %s", name)
+	return nil
+}
+
+// lookup finds the recorded metadata and backend for a snapshot.
+// If no metadata was recorded, the snapshot is assumed to be in the cluster's current backend.
+func (s *Snapshotter) lookup(ctx context.Context, name string) (*Metadata, Backend, error) {
+	if err := validateName(name); err != nil {
+		return nil, nil, err
+	}
+	m, err := getMetadata(ctx, s.ki, s.v.Namespace, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := ConfigFromVariables(s.v)
+	if m != nil {
+		cfg = m.Backend
+	}
+	backend, err := NewBackend(ctx, cfg, s.v)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m == nil {
+		m = &Metadata{
+			Name:     name,
+			Cluster:  s.v.Name,
+			Key:      s.key(name),
+			Location: backend.Location(s.key(name)),
+			Backend:  backend.Config(),
+		}
+	}
+	return m, backend, nil
+}
+
+// resources are the resources the cluster's snapshots capture.
+// Snapshots are stored unencrypted, so Secrets are only captured if the cluster opts in.
+func (s *Snapshotter) resources() []schema.GroupVersionResource {
+	var resources []schema.GroupVersionResource
+	for _, gvr := range Resources {
+		if gvr.Resource == "secrets" && !s.v.SnapshotSecrets {
+			continue
+		}
+		resources = append(resources, gvr)
+	}
+	return resources
+}
+
+func (s *Snapshotter) key(name string) string {
+	return fmt.Sprintf("%s/%s.json", s.v.Name, name)
+}
+
+func validateName(name string) error {
+	if !validSnapshotName.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return nil
+}
+
+// listRestorable lists the objects of a resource that can be restored, stripped of server populated fields
+func listRestorable(ctx context.Context, di dynamic.Interface, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	list, err := di.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// the resource is not served by this cluster version
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var objects []unstructured.Unstructured
+	for idx := range list.Items {
+		u := &list.Items[idx]
+		if !isRestorable(gvr, u) {
+			continue
+		}
+		sanitize(gvr, u)
+		objects = append(objects, *u)
+	}
+	return objects, nil
+}
+
+func isRestorable(gvr schema.GroupVersionResource, u *unstructured.Unstructured) bool {
+	// objects owned by another object are recreated by their owner's controller
+	if len(u.GetOwnerReferences()) > 0 {
+		return false
+	}
+	if systemNamespaces[u.GetNamespace()] {
+		return false
+	}
+	switch gvr.Resource {
+	case "namespaces":
+		// the default namespace always exists, but its objects are captured
+		return !systemNamespaces[u.GetName()] && u.GetName() != "default"
+	case "secrets":
+		secretType, _, _ := unstructured.NestedString(u.Object, "type")
+		return secretType != serviceAccountTokenType
+	case "configmaps":
+		return u.GetName() != "kube-root-ca.crt"
+	case "serviceaccounts":
+		return u.GetName() != "default"
+	case "clusterroles", "clusterrolebindings":
+		_, bootstrapped := u.GetLabels()[rbacBootstrappingLabel]
+		return !bootstrapped && !strings.HasPrefix(u.GetName(), "system:")
+	}
+	return true
+}
+
+func sanitize(gvr schema.GroupVersionResource, u *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(u.Object, "status")
+	for _, field := range []string{"uid", "resourceVersion", "creationTimestamp", "generation", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	// cluster IPs are allocated when the service is restored
+	if gvr.Resource == "services" {
+		unstructured.RemoveNestedField(u.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(u.Object, "spec", "clusterIPs")
+	}
+}
+
+func restoreObject(ctx context.Context, di dynamic.Interface, gvr schema.GroupVersionResource, u *unstructured.Unstructured) error {
+	var client dynamic.ResourceInterface = di.Resource(gvr)
+	if u.GetNamespace() != "" {
+		client = di.Resource(gvr).Namespace(u.GetNamespace())
+	}
+	existing, err := client.Get(ctx, u.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := client.Create(ctx, u, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("restore create failed %s %s/%s: %v", gvr.Resource, u.GetNamespace(), u.GetName(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("restore get failed %s %s/%s: %v", gvr.Resource, u.GetNamespace(), u.GetName(), err)
+	}
+	u.SetResourceVersion(existing.GetResourceVersion())
+	if _, err := client.Update(ctx, u, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("restore update failed %s %s/%s: %v", gvr.Resource, u.GetNamespace(), u.GetName(), err)
+	}
+	return nil
+}
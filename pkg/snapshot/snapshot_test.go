@@ -0,0 +1,274 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci"
+	ocifake "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci/fake"
+	fakelogger "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/provisioning/fake"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/variables"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+)
+
+const (
+	testCluster = "kluster"
+)
+
+func TestSaveRestoreRemove(t *testing.T) {
+	var tests = []struct {
+		name    string
+		v       *variables.Variables
+		storage *ocifake.ObjectStorage
+	}{
+		{
+			"filesystem backend",
+			&variables.Variables{
+				Name:              testCluster,
+				Namespace:         testCluster,
+				SnapshotBackend:   BackendFilesystem,
+				SnapshotDirectory: t.TempDir(),
+			},
+			nil,
+		},
+		{
+			"objectstorage backend",
+			&variables.Variables{
+				Name:           testCluster,
+				Namespace:      testCluster,
+				SnapshotBucket: "snapshots",
+			},
+			&ocifake.ObjectStorage{
+				Namespace: "tenancy",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			ObjectStorageGetter = func(_ *variables.Variables) (oci.ObjectStorage, error) {
+				return tt.storage, nil
+			}
+			ki := fake.NewSimpleClientset()
+			s := NewSnapshotter(ki, tt.v, fakelogger.NewLogger())
+
+			m, err := s.Save(ctx, createTestManagedDI(), "snap-1")
+			assert.NoError(t, err)
+			assert.Equal(t, 4, m.Objects)
+			assert.Equal(t, testCluster+"/snap-1.json", m.Key)
+			if tt.storage != nil {
+				assert.Equal(t, "oci://snapshots@tenancy/kluster/snap-1.json", m.Location)
+			}
+
+			recorded, err := getMetadata(ctx, ki, testCluster, "snap-1")
+			assert.NoError(t, err)
+			assert.Equal(t, m, recorded)
+
+			// restore into an empty cluster
+			restored := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds())
+			_, err = s.Restore(ctx, restored, "snap-1")
+			assert.NoError(t, err)
+			cm, err := restored.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("app").Get(ctx, "app-config", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, "", cm.GetResourceVersion())
+			_, err = restored.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).Get(ctx, "kube-system", metav1.GetOptions{})
+			assert.Error(t, err)
+			// workloads in the default namespace are restored, but not the namespace itself
+			_, err = restored.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("default").Get(ctx, "web-config", metav1.GetOptions{})
+			assert.NoError(t, err)
+			_, err = restored.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).Get(ctx, "default", metav1.GetOptions{})
+			assert.Error(t, err)
+
+			// restore over existing objects
+			_, err = s.Restore(ctx, restored, "snap-1")
+			assert.NoError(t, err)
+
+			assert.NoError(t, s.Remove(ctx, "snap-1"))
+			recorded, err = getMetadata(ctx, ki, testCluster, "snap-1")
+			assert.NoError(t, err)
+			assert.Nil(t, recorded)
+			_, err = s.Restore(ctx, restored, "snap-1")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestInvalidSnapshotName(t *testing.T) {
+	s := NewSnapshotter(fake.NewSimpleClientset(), &variables.Variables{
+		Name:            testCluster,
+		Namespace:       testCluster,
+		SnapshotBackend: BackendFilesystem,
+	}, fakelogger.NewLogger())
+	_, err := s.Save(context.TODO(), createTestManagedDI(), "../etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestSaveSecrets(t *testing.T) {
+	var tests = []struct {
+		name            string
+		snapshotSecrets bool
+		objects         int
+	}{
+		{
+			"secrets are not captured by default",
+			false,
+			4,
+		},
+		{
+			"secrets are captured if the cluster opts in",
+			true,
+			5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSnapshotter(fake.NewSimpleClientset(), &variables.Variables{
+				Name:              testCluster,
+				Namespace:         testCluster,
+				SnapshotBackend:   BackendFilesystem,
+				SnapshotDirectory: t.TempDir(),
+				SnapshotSecrets:   tt.snapshotSecrets,
+			}, fakelogger.NewLogger())
+			m, err := s.Save(context.TODO(), createTestManagedDI(), "snap-1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.objects, m.Objects)
+		})
+	}
+}
+
+func TestConfigFromVariables(t *testing.T) {
+	var tests = []struct {
+		name    string
+		v       *variables.Variables
+		backend string
+	}{
+		{
+			"no snapshot settings",
+			&variables.Variables{},
+			"",
+		},
+		{
+			"filesystem",
+			&variables.Variables{SnapshotBackend: BackendFilesystem},
+			BackendFilesystem,
+		},
+		{
+			"bucket",
+			&variables.Variables{SnapshotBucket: "snapshots"},
+			BackendObjectStorage,
+		},
+		{
+			"explicit backend",
+			&variables.Variables{SnapshotBackend: BackendObjectStorage},
+			BackendObjectStorage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.backend, ConfigFromVariables(tt.v).Type)
+		})
+	}
+}
+
+func TestMetadataConflicts(t *testing.T) {
+	ctx := context.TODO()
+	ki := fake.NewSimpleClientset()
+	assert.NoError(t, putMetadata(ctx, ki, testCluster, &Metadata{Name: "snap-1"}))
+	// the first update of the index conflicts with another snapshot's update
+	conflicts := 0
+	ki.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, apierrors.NewConflict(corev1.Resource("configmaps"), metadataConfigMapName, errors.New("the object has been modified"))
+	})
+	assert.NoError(t, putMetadata(ctx, ki, testCluster, &Metadata{Name: "snap-2"}))
+	conflicts = 0
+	assert.NoError(t, deleteMetadata(ctx, ki, testCluster, "snap-1"))
+
+	m, err := getMetadata(ctx, ki, testCluster, "snap-2")
+	assert.NoError(t, err)
+	assert.NotNil(t, m)
+	m, err = getMetadata(ctx, ki, testCluster, "snap-1")
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+}
+
+func TestBackendRequiresSnapshotSettings(t *testing.T) {
+	s := NewSnapshotter(fake.NewSimpleClientset(), &variables.Variables{
+		Name:      testCluster,
+		Namespace: testCluster,
+	}, fakelogger.NewLogger())
+	_, err := s.Save(context.TODO(), createTestManagedDI(), "snap-1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "snapshots require a snapshot-bucket")
+}
+
+func TestObjectStorageBackendRequiresBucket(t *testing.T) {
+	_, err := NewBackend(context.TODO(), BackendConfig{Type: BackendObjectStorage}, &variables.Variables{})
+	assert.Error(t, err)
+}
+
+func createTestManagedDI() dynamic.Interface {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds(),
+		testObject("v1", "Namespace", "", "app", nil),
+		testObject("v1", "Namespace", "", "kube-system", nil),
+		testObject("v1", "Namespace", "", "default", nil),
+		testObject("v1", "ConfigMap", "default", "web-config", nil),
+		testObject("v1", "ConfigMap", "app", "app-config", map[string]interface{}{
+			"data": map[string]interface{}{"key": "value"},
+		}),
+		testObject("v1", "ConfigMap", "app", "kube-root-ca.crt", nil),
+		testObject("v1", "Secret", "app", "app-token", map[string]interface{}{
+			"type": serviceAccountTokenType,
+		}),
+		testObject("v1", "Secret", "app", "app-credentials", map[string]interface{}{
+			"type": "Opaque",
+			"data": map[string]interface{}{"password": "c2VjcmV0"},
+		}),
+		testObject("apps/v1", "Deployment", "app", "app", map[string]interface{}{
+			"spec":   map[string]interface{}{"replicas": int64(1)},
+			"status": map[string]interface{}{"readyReplicas": int64(1)},
+		}),
+		testObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "system:controller", nil),
+	)
+}
+
+func testObject(apiVersion, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for k, v := range fields {
+		u.Object[k] = v
+	}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetResourceVersion("100")
+	return u
+}
+
+// listKinds registers a list kind for every snapshot resource, so the fake client can list them
+func listKinds() map[schema.GroupVersionResource]string {
+	kinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range Resources {
+		kinds[gvr] = gvr.Resource + "List"
+	}
+	return kinds
+}
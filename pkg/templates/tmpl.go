@@ -28,3 +28,6 @@ var VerrazzanoFleet string
 
 //go:embed imagepullsecret.goyaml
 var ImagePullSecret string
+
+//go:embed clusterautoscaler.goyaml
+var ClusterAutoscaler string
@@ -0,0 +1,75 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"encoding/json"
+	driverconst "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/constants"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// clusterAutoscalerAddon is the OKE add-on that manages node pool replicas
+const clusterAutoscalerAddon = "ClusterAutoscaler"
+
+// Addon is an OKE cluster add-on, such as CoreDNS, KubeProxy, CertManager, ClusterAutoscaler or NativeIngressController.
+// Add-ons removed from the cluster's add-ons are disabled.
+type Addon struct {
+	Name string `json:"name"`
+	// Version is the add-on version. Add-ons without a version are kept at the latest version by OKE.
+	Version string `json:"version,omitempty"`
+	// Configurations are the add-on's configuration keys and values
+	Configurations map[string]string `json:"configurations,omitempty"`
+}
+
+// ParseAddons deserializes the cluster's add-ons
+func (v *Variables) ParseAddons() ([]Addon, error) {
+	addons, errs := v.parseAddons(field.NewPath(driverconst.RawAddons))
+	if len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return addons, nil
+}
+
+// parseAddons deserializes the add-ons that are valid JSON, and describes the others
+func (v *Variables) parseAddons(path *field.Path) ([]Addon, field.ErrorList) {
+	var addons []Addon
+	var errs field.ErrorList
+
+	for i, rawAddon := range v.RawAddons {
+		addon := Addon{}
+		if err := json.Unmarshal([]byte(rawAddon), &addon); err != nil {
+			errs = append(errs, field.Invalid(path.Index(i), rawAddon, err.Error()))
+			continue
+		}
+		addons = append(addons, addon)
+	}
+
+	return addons, errs
+}
+
+func validateAddons(path *field.Path, v *Variables) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{}
+	for i, addon := range v.Addons {
+		addonPath := path.Index(i)
+		if addon.Name == "" {
+			errs = append(errs, field.Required(addonPath.Child("name"), "add-ons must be named"))
+		} else {
+			if names[addon.Name] {
+				errs = append(errs, field.Duplicate(addonPath.Child("name"), addon.Name))
+			}
+			names[addon.Name] = true
+		}
+		// the driver deploys its own cluster autoscaler, which would compete with the add-on for the same node pools
+		if addon.Name == clusterAutoscalerAddon && v.IsAutoscaled() {
+			errs = append(errs, field.Forbidden(addonPath.Child("name"), "the ClusterAutoscaler add-on can't be used with autoscaled node pools, which are managed by the driver's cluster autoscaler"))
+		}
+		for key := range addon.Configurations {
+			if key == "" {
+				errs = append(errs, field.Required(addonPath.Child("configurations"), "add-on configurations must have a key"))
+			}
+		}
+	}
+	return errs
+}
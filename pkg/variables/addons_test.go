@@ -0,0 +1,63 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAddons(t *testing.T) {
+	var tests = []struct {
+		name     string
+		rawAddon string
+		addon    Addon
+		hasError bool
+	}{
+		{
+			"name only",
+			`{"name":"CertManager"}`,
+			Addon{Name: "CertManager"},
+			false,
+		},
+		{
+			"version and configurations",
+			`{"name":"CoreDNS","version":"v1.10.1","configurations":{"minReplica":"2","nodesPerReplica":"4"}}`,
+			Addon{
+				Name:    "CoreDNS",
+				Version: "v1.10.1",
+				Configurations: map[string]string{
+					"minReplica":      "2",
+					"nodesPerReplica": "4",
+				},
+			},
+			false,
+		},
+		{
+			"configuration values must be strings",
+			`{"name":"CoreDNS","configurations":{"minReplica":2}}`,
+			Addon{},
+			true,
+		},
+		{
+			"not JSON",
+			`CertManager`,
+			Addon{},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{RawAddons: []string{tt.rawAddon}}
+			addons, err := v.ParseAddons()
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []Addon{tt.addon}, addons)
+		})
+	}
+}
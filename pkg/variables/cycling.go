@@ -0,0 +1,121 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultNodeCyclingMaxSurge       = "1"
+	DefaultNodeCyclingMaxUnavailable = "0"
+	DefaultNodeEvictionGraceDuration = "PT1H"
+
+	// OKE waits at most an hour for nodes to drain
+	maxEvictionGraceDuration = time.Hour
+)
+
+var (
+	// nodeCountPattern matches a number of nodes, e.g. 2, or a percentage of the pool's nodes, e.g. 25%
+	nodeCountPattern = regexp.MustCompile(`^([0-9]+)(%?)$`)
+	// evictionGraceDurationPattern matches ISO 8601 durations in hours, minutes and seconds, e.g. PT1H or PT20M
+	evictionGraceDurationPattern = regexp.MustCompile(`^PT(?:([0-9]+)H)?(?:([0-9]+)M)?(?:([0-9]+)S)?$`)
+)
+
+// NodeCycling is how a node pool's nodes are replaced, and drained when they are deleted
+type NodeCycling struct {
+	MaxSurge              string
+	MaxUnavailable        string
+	EvictionGraceDuration string
+	ForceDelete           bool
+}
+
+// NodeCycling is a node pool's cycling settings. Settings the pool doesn't set use the cluster's settings.
+func (v Variables) NodeCycling(np NodePool) NodeCycling {
+	cycling := NodeCycling{
+		MaxSurge:              firstNonEmpty(np.MaxSurge, v.NodeCyclingMaxSurge, DefaultNodeCyclingMaxSurge),
+		MaxUnavailable:        firstNonEmpty(np.MaxUnavailable, v.NodeCyclingMaxUnavailable, DefaultNodeCyclingMaxUnavailable),
+		EvictionGraceDuration: firstNonEmpty(np.EvictionGraceDuration, v.NodeEvictionGraceDuration, DefaultNodeEvictionGraceDuration),
+		ForceDelete:           v.NodeEvictionForceDelete,
+	}
+	if np.ForceDeleteAfterGraceDuration != nil {
+		cycling.ForceDelete = *np.ForceDeleteAfterGraceDuration
+	}
+	return cycling
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// validateNodeCycling checks a node pool's cycling settings, and that its nodes can be cycled
+func validateNodeCycling(path *field.Path, np NodePool, cycling NodeCycling) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateNodeCount(path.Child("maxSurge"), np.MaxSurge)...)
+	errs = append(errs, validateNodeCount(path.Child("maxUnavailable"), np.MaxUnavailable)...)
+	errs = append(errs, validateEvictionGraceDuration(path.Child("evictionGraceDuration"), np.EvictionGraceDuration)...)
+	if len(errs) == 0 && isZeroNodeCount(cycling.MaxSurge) && isZeroNodeCount(cycling.MaxUnavailable) {
+		errs = append(errs, field.Invalid(path.Child("maxUnavailable"), cycling.MaxUnavailable, "must be greater than 0 when maxSurge is 0, or nodes can't be cycled"))
+	}
+	return errs
+}
+
+// validateNodeCount checks a number of nodes, or a percentage of a pool's nodes
+func validateNodeCount(path *field.Path, count string) field.ErrorList {
+	if count == "" {
+		return nil
+	}
+	match := nodeCountPattern.FindStringSubmatch(count)
+	if match == nil {
+		return field.ErrorList{field.Invalid(path, count, "must be a number of nodes, e.g. 2, or a percentage of the pool's nodes, e.g. 25%")}
+	}
+	if n, _ := strconv.Atoi(match[1]); match[2] == "%" && n > 100 {
+		return field.ErrorList{field.Invalid(path, count, "must be a percentage from 0% to 100%")}
+	}
+	return nil
+}
+
+func isZeroNodeCount(count string) bool {
+	return strings.TrimSuffix(count, "%") == "0"
+}
+
+func validateEvictionGraceDuration(path *field.Path, duration string) field.ErrorList {
+	if duration == "" {
+		return nil
+	}
+	d, err := parseEvictionGraceDuration(duration)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, duration, err.Error())}
+	}
+	if d > maxEvictionGraceDuration {
+		return field.ErrorList{field.Invalid(path, duration, "must be at most PT60M")}
+	}
+	return nil
+}
+
+// parseEvictionGraceDuration parses an ISO 8601 duration, e.g. PT20M
+func parseEvictionGraceDuration(duration string) (time.Duration, error) {
+	match := evictionGraceDurationPattern.FindStringSubmatch(duration)
+	if match == nil || duration == "PT" {
+		return 0, fmt.Errorf("must be an ISO 8601 duration, e.g. PT20M")
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		if match[i+1] != "" {
+			n, _ := strconv.Atoi(match[i+1])
+			d += time.Duration(n) * unit
+		}
+	}
+	return d, nil
+}
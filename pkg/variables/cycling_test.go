@@ -0,0 +1,73 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNodeCycling(t *testing.T) {
+	forceDelete := false
+	var tests = []struct {
+		name string
+		v    Variables
+		np   NodePool
+		res  NodeCycling
+	}{
+		{
+			"defaults",
+			Variables{},
+			NodePool{},
+			NodeCycling{MaxSurge: "1", MaxUnavailable: "0", EvictionGraceDuration: "PT1H"},
+		},
+		{
+			"cluster settings",
+			Variables{NodeCyclingMaxSurge: "25%", NodeCyclingMaxUnavailable: "1", NodeEvictionGraceDuration: "PT20M", NodeEvictionForceDelete: true},
+			NodePool{},
+			NodeCycling{MaxSurge: "25%", MaxUnavailable: "1", EvictionGraceDuration: "PT20M", ForceDelete: true},
+		},
+		{
+			"node pool settings",
+			Variables{NodeCyclingMaxSurge: "25%", NodeEvictionForceDelete: true},
+			NodePool{MaxSurge: "0", MaxUnavailable: "1", EvictionGraceDuration: "PT45M", ForceDeleteAfterGraceDuration: &forceDelete},
+			NodeCycling{MaxSurge: "0", MaxUnavailable: "1", EvictionGraceDuration: "PT45M"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.res, tt.v.NodeCycling(tt.np))
+		})
+	}
+}
+
+func TestParseEvictionGraceDuration(t *testing.T) {
+	var tests = []struct {
+		duration string
+		res      time.Duration
+		hasError bool
+	}{
+		{"PT1H", time.Hour, false},
+		{"PT20M", 20 * time.Minute, false},
+		{"PT5M30S", 5*time.Minute + 30*time.Second, false},
+		{"PT0M", 0, false},
+		{"PT", 0, true},
+		{"20m", 0, true},
+		{"P1D", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.duration, func(t *testing.T) {
+			d, err := parseEvictionGraceDuration(tt.duration)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.res, d)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// DistributionProportional spreads nodes across pools in proportion to their current size
+	DistributionProportional = "proportional"
+	// DistributionFirstPool only resizes the first node pool
+	DistributionFirstPool = "first-pool"
+	// DistributionEven spreads nodes evenly across pools
+	DistributionEven = "even"
+)
+
+// DistributeNodeCount splits count nodes across the node pools using a distribution policy.
+// Each pool's replicas are kept within the pool's minSize and maxSize, autoscaled pools keep their configured replicas,
+// and the result always sums to count.
+func DistributeNodeCount(nodePools []NodePool, count int64, policy string) ([]int64, error) {
+	if len(nodePools) < 1 {
+		return nil, errors.New("cluster has no node pools to resize")
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("invalid node count %d", count)
+	}
+	mins := make([]int64, len(nodePools))
+	maxs := make([]int64, len(nodePools))
+	weights := make([]int64, len(nodePools))
+	for i, np := range nodePools {
+		if np.MaxSize > 0 && np.MinSize > np.MaxSize {
+			return nil, fmt.Errorf("node pool %s has minSize %d greater than maxSize %d", np.Name, np.MinSize, np.MaxSize)
+		}
+		mins[i] = np.MinSize
+		maxs[i] = np.MaxSize
+		if maxs[i] == 0 {
+			maxs[i] = math.MaxInt64
+		}
+		// autoscaled pools are sized by the cluster autoscaler, and keep their configured replicas
+		if np.Autoscaled() {
+			mins[i] = np.Replicas
+			maxs[i] = np.Replicas
+		}
+	}
+
+	switch policy {
+	case "", DistributionProportional:
+		var total int64
+		for i, np := range nodePools {
+			weights[i] = np.Replicas
+			total += np.Replicas
+		}
+		// pools with no nodes have no proportion, so spread evenly
+		if total == 0 {
+			for i := range weights {
+				weights[i] = 1
+			}
+		}
+	case DistributionEven:
+		for i := range nodePools {
+			weights[i] = 1
+		}
+	case DistributionFirstPool:
+		// every pool but the first keeps its current size
+		weights[0] = 1
+		for i := 1; i < len(nodePools); i++ {
+			mins[i] = nodePools[i].Replicas
+			maxs[i] = nodePools[i].Replicas
+		}
+	default:
+		return nil, fmt.Errorf("unknown node pool distribution %s, must be one of %s, %s or %s", policy, DistributionProportional, DistributionFirstPool, DistributionEven)
+	}
+
+	return allocate(nodePools, count, weights, mins, maxs)
+}
+
+// allocate starts each pool at its minimum, and then assigns the remaining nodes one at a time
+// to the pool furthest below its weighted share that still has room (the D'Hondt method).
+func allocate(nodePools []NodePool, count int64, weights, mins, maxs []int64) ([]int64, error) {
+	res := make([]int64, len(nodePools))
+	var left = count
+	for i := range res {
+		res[i] = mins[i]
+		left -= mins[i]
+	}
+	if left < 0 {
+		return nil, fmt.Errorf("cannot resize cluster to %d nodes, node pools require at least %d nodes", count, count-left)
+	}
+	for ; left > 0; left-- {
+		next := -1
+		var nextPriority float64
+		for i := range res {
+			if res[i] >= maxs[i] {
+				continue
+			}
+			priority := float64(weights[i]) / float64(res[i]+1)
+			// ties go to the smaller pool, e.g. pools without weight once every weighted pool is full
+			if next < 0 || priority > nextPriority || (priority == nextPriority && res[i] < res[next]) {
+				next = i
+				nextPriority = priority
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("cannot resize cluster to %d nodes, node pools allow at most %d nodes", count, count-left)
+		}
+		res[next]++
+	}
+	return res, nil
+}
+
+// SetNodePoolReplicas sets the replicas of each node pool, in both the parsed and raw node pools
+func (v *Variables) SetNodePoolReplicas(replicas []int64) error {
+	if len(replicas) != len(v.NodePools) || len(replicas) != len(v.RawNodePools) {
+		return errors.New("node pool replicas do not match the cluster's node pools")
+	}
+	for i, rawNodePool := range v.RawNodePools {
+		// update the raw JSON in place, so user supplied fields are preserved as-is
+		np := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(rawNodePool), &np); err != nil {
+			return err
+		}
+		np["replicas"] = replicas[i]
+		b, err := json.Marshal(np)
+		if err != nil {
+			return err
+		}
+		v.RawNodePools[i] = string(b)
+		v.NodePools[i].Replicas = replicas[i]
+	}
+	return nil
+}
+
+// DescribeNodePoolReplicas describes the replicas of each node pool, e.g. "np-1=3, np-2=4"
+func DescribeNodePoolReplicas(nodePools []NodePool, replicas []int64) string {
+	var pools []string
+	for i, np := range nodePools {
+		if i < len(replicas) {
+			pools = append(pools, fmt.Sprintf("%s=%d", np.Name, replicas[i]))
+		}
+	}
+	return strings.Join(pools, ", ")
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDistributeNodeCount(t *testing.T) {
+	threePools := []NodePool{
+		{Name: "np-1", Replicas: 1},
+		{Name: "np-2", Replicas: 2},
+		{Name: "np-3", Replicas: 3},
+	}
+	var tests = []struct {
+		name      string
+		nodePools []NodePool
+		count     int64
+		policy    string
+		res       []int64
+		hasError  bool
+	}{
+		{
+			"proportional growth",
+			threePools,
+			12,
+			DistributionProportional,
+			[]int64{2, 4, 6},
+			false,
+		},
+		{
+			"proportional is the default",
+			threePools,
+			3,
+			"",
+			[]int64{0, 1, 2},
+			false,
+		},
+		{
+			"proportional from empty pools is even",
+			[]NodePool{{Name: "np-1"}, {Name: "np-2"}},
+			3,
+			DistributionProportional,
+			[]int64{2, 1},
+			false,
+		},
+		{
+			"even spread",
+			threePools,
+			7,
+			DistributionEven,
+			[]int64{3, 2, 2},
+			false,
+		},
+		{
+			"first pool only",
+			threePools,
+			10,
+			DistributionFirstPool,
+			[]int64{5, 2, 3},
+			false,
+		},
+		{
+			"first pool only cannot remove other pools' nodes",
+			threePools,
+			4,
+			DistributionFirstPool,
+			nil,
+			true,
+		},
+		{
+			"bounds are respected",
+			[]NodePool{
+				{Name: "np-1", Replicas: 1, MinSize: 2},
+				{Name: "np-2", Replicas: 9, MaxSize: 5},
+			},
+			10,
+			DistributionProportional,
+			[]int64{5, 5},
+			false,
+		},
+		{
+			"below minimum",
+			[]NodePool{{Name: "np-1", MinSize: 2}, {Name: "np-2", MinSize: 2}},
+			3,
+			DistributionEven,
+			nil,
+			true,
+		},
+		{
+			"above maximum",
+			[]NodePool{{Name: "np-1", MaxSize: 2}, {Name: "np-2", MaxSize: 2}},
+			5,
+			DistributionEven,
+			nil,
+			true,
+		},
+		{
+			"autoscaled pools keep their size",
+			[]NodePool{
+				{Name: "np-1", Replicas: 2},
+				{Name: "np-2", Replicas: 3, MaxReplicas: 10},
+			},
+			8,
+			DistributionEven,
+			[]int64{5, 3},
+			false,
+		},
+		{
+			"first pool only keeps empty pools empty",
+			[]NodePool{{Name: "np-1", Replicas: 1}, {Name: "np-2"}},
+			4,
+			DistributionFirstPool,
+			[]int64{4, 0},
+			false,
+		},
+		{
+			"unknown policy",
+			threePools,
+			3,
+			"random",
+			nil,
+			true,
+		},
+		{
+			"no node pools",
+			nil,
+			3,
+			DistributionEven,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := DistributeNodeCount(tt.nodePools, tt.count, tt.policy)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.res, res)
+		})
+	}
+}
+
+func TestSetNodePoolReplicas(t *testing.T) {
+	v := &Variables{
+		RawNodePools: []string{
+			"{\"name\":\"np-1\",\"replicas\":2,\"shape\":\"VM.Standard.E4.Flex\"}",
+			"{\"name\":\"np-2\",\"replicas\":4,\"shape\":\"VM.Standard.E4.Flex\"}",
+		},
+	}
+	nps, err := v.ParseNodePools()
+	assert.NoError(t, err)
+	v.NodePools = nps
+
+	assert.NoError(t, v.SetNodePoolReplicas([]int64{3, 5}))
+	nc, err := v.NodeCount()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), nc.Count)
+	assert.Equal(t, "np-1=3, np-2=5", DescribeNodePoolReplicas(v.NodePools, []int64{3, 5}))
+	assert.Error(t, v.SetNodePoolReplicas([]int64{1}))
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"context"
+	"fmt"
+	"github.com/oracle/oci-go-sdk/v65/keymanagement"
+	driverconst "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/constants"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validateKMSKeys checks the cluster's KMS keys are enabled keys in the cluster's compartment.
+// Encryption keys must be AES keys, and image verification keys must be RSA keys.
+func (v *Variables) validateKMSKeys(ctx context.Context, client oci.Client) error {
+	type kmsKey struct {
+		path      *field.Path
+		id        string
+		algorithm keymanagement.KeySummaryAlgorithmEnum
+	}
+	var kmsKeys []kmsKey
+	if v.KMSKeyID != "" {
+		kmsKeys = append(kmsKeys, kmsKey{field.NewPath(driverconst.KMSKeyID), v.KMSKeyID, keymanagement.KeySummaryAlgorithmAes})
+	}
+	for i, np := range v.NodePools {
+		if np.BootVolumeKMSKeyID != "" {
+			kmsKeys = append(kmsKeys, kmsKey{field.NewPath(driverconst.RawNodePools).Index(i).Child("bootVolumeKmsKeyId"), np.BootVolumeKMSKeyID, keymanagement.KeySummaryAlgorithmAes})
+		}
+	}
+	for i, id := range v.ImageVerificationKMSKeyIDs {
+		kmsKeys = append(kmsKeys, kmsKey{field.NewPath(driverconst.ImageVerificationKMSKeyIDs).Index(i), id, keymanagement.KeySummaryAlgorithmRsa})
+	}
+	if len(kmsKeys) == 0 {
+		return nil
+	}
+
+	keys, err := client.GetKMSKeys(ctx, v.CompartmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get KMS keys: %v", err)
+	}
+	compartmentKeys := map[string]keymanagement.KeySummary{}
+	for _, key := range keys {
+		compartmentKeys[*key.Id] = key
+	}
+	var errs field.ErrorList
+	for _, k := range kmsKeys {
+		key, ok := compartmentKeys[k.id]
+		switch {
+		case !ok:
+			errs = append(errs, field.NotFound(k.path, k.id))
+		case key.LifecycleState != keymanagement.KeySummaryLifecycleStateEnabled:
+			errs = append(errs, field.Invalid(k.path, k.id, fmt.Sprintf("key is %s, and must be ENABLED", key.LifecycleState)))
+		case key.Algorithm != k.algorithm:
+			errs = append(errs, field.Invalid(k.path, k.id, fmt.Sprintf("key is an %s key, and must be an %s key", key.Algorithm, k.algorithm)))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid KMS keys: %v", errs.ToAggregate())
+	}
+	return nil
+}
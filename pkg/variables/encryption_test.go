@@ -0,0 +1,127 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"context"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/keymanagement"
+	"github.com/stretchr/testify/assert"
+	ocifake "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci/fake"
+	"testing"
+)
+
+const (
+	testKMSKey         = "ocid1.key.oc1.phx.aes"
+	testDisabledKMSKey = "ocid1.key.oc1.phx.disabled"
+	testRSAKMSKey      = "ocid1.key.oc1.phx.rsa"
+)
+
+func testKMSClient() *ocifake.Client {
+	return &ocifake.Client{
+		KMSKeys: []keymanagement.KeySummary{
+			{Id: common.String(testKMSKey), LifecycleState: keymanagement.KeySummaryLifecycleStateEnabled, Algorithm: keymanagement.KeySummaryAlgorithmAes},
+			{Id: common.String(testDisabledKMSKey), LifecycleState: keymanagement.KeySummaryLifecycleStateDisabled, Algorithm: keymanagement.KeySummaryAlgorithmAes},
+			{Id: common.String(testRSAKMSKey), LifecycleState: keymanagement.KeySummaryLifecycleStateEnabled, Algorithm: keymanagement.KeySummaryAlgorithmRsa},
+		},
+	}
+}
+
+func TestValidateKMSKeys(t *testing.T) {
+	client := testKMSClient()
+	var tests = []struct {
+		name     string
+		secrets  string
+		boot     string
+		hasError bool
+	}{
+		{
+			"no keys",
+			"",
+			"",
+			false,
+		},
+		{
+			"enabled AES keys",
+			testKMSKey,
+			testKMSKey,
+			false,
+		},
+		{
+			"key outside the compartment",
+			"ocid1.key.oc1.phx.other",
+			"",
+			true,
+		},
+		{
+			"disabled key",
+			"",
+			testDisabledKMSKey,
+			true,
+		},
+		{
+			"RSA key",
+			testRSAKMSKey,
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{
+				KMSKeyID: tt.secrets,
+				NodePools: []NodePool{
+					{Name: "np", BootVolumeKMSKeyID: tt.boot},
+				},
+			}
+			err := v.validateKMSKeys(context.TODO(), client)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateImageVerificationKMSKeys(t *testing.T) {
+	client := testKMSClient()
+	var tests = []struct {
+		name     string
+		keys     []string
+		hasError bool
+	}{
+		{
+			"RSA keys",
+			[]string{testRSAKMSKey},
+			false,
+		},
+		{
+			"AES key",
+			[]string{testRSAKMSKey, testKMSKey},
+			true,
+		},
+		{
+			"key outside the compartment",
+			[]string{"ocid1.key.oc1.phx.other"},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{
+				ImageVerification:          true,
+				ImageVerificationKMSKeyIDs: tt.keys,
+			}
+			err := v.validateKMSKeys(context.TODO(), client)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
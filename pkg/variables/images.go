@@ -0,0 +1,98 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"context"
+	"fmt"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci"
+	"regexp"
+	"strings"
+)
+
+const (
+	architectureX86     = "x86_64"
+	architectureAarch64 = "aarch64"
+)
+
+// ampereShapePattern matches Ampere shapes, e.g. VM.Standard.A1.Flex
+var ampereShapePattern = regexp.MustCompile(`\.A[0-9]+\.`)
+
+// setNodePoolImages resolves each node pool's image, and checks it can run on the pool's shape.
+// Pools without an image use the cluster's image.
+func (v *Variables) setNodePoolImages(ctx context.Context, client oci.Client, shapes map[string]core.Shape) error {
+	for i := range v.NodePools {
+		np := &v.NodePools[i]
+		imageId, displayName := v.ActualImage, v.ImageDisplayName
+		if np.Image != "" {
+			var err error
+			imageId, displayName, err = resolveImage(ctx, client, np.Image, v.CompartmentID)
+			if err != nil {
+				return fmt.Errorf("node pool %s: %v", np.Name, err)
+			}
+		}
+		if shape, ok := shapes[np.Shape]; ok {
+			if err := checkImageArchitecture(displayName, shape); err != nil {
+				return fmt.Errorf("node pool %s: %v", np.Name, err)
+			}
+		}
+		np.ActualImage = imageId
+	}
+	return nil
+}
+
+// resolveImage finds an image by OCID or by display name, returning the image's OCID and display name
+func resolveImage(ctx context.Context, client oci.Client, image, compartmentId string) (string, string, error) {
+	if match := ocidPattern.FindStringSubmatch(image); match != nil && match[1] == "image" {
+		i, err := client.GetImageById(ctx, image)
+		if err != nil {
+			return "", "", err
+		}
+		var displayName string
+		if i.DisplayName != nil {
+			displayName = *i.DisplayName
+		}
+		return image, displayName, nil
+	}
+	imageId, err := client.GetImageIdByName(ctx, image, compartmentId)
+	if err != nil {
+		return "", "", err
+	}
+	return imageId, image, nil
+}
+
+// checkImageArchitecture checks an image is built for the shape's processor architecture, and that GPU images are only used with GPU shapes
+func checkImageArchitecture(displayName string, shape core.Shape) error {
+	imageArch, imageGPU := imageArchitecture(displayName)
+	shapeArch, shapeGPU := shapeArchitecture(shape)
+	if imageArch != shapeArch {
+		return fmt.Errorf("image %s is built for %s, but shape %s is %s", displayName, imageArch, *shape.Shape, shapeArch)
+	}
+	if imageGPU != shapeGPU {
+		if imageGPU {
+			return fmt.Errorf("image %s is a GPU image, but shape %s has no GPUs", displayName, *shape.Shape)
+		}
+		return fmt.Errorf("shape %s has GPUs, and requires a GPU image", *shape.Shape)
+	}
+	return nil
+}
+
+// imageArchitecture is the architecture of an OKE image, from its display name, e.g. Oracle-Linux-8.7-aarch64-2023.05.24-0-OKE-1.26.2-625
+func imageArchitecture(displayName string) (string, bool) {
+	gpu := strings.Contains(displayName, "GPU")
+	if strings.Contains(displayName, architectureAarch64) {
+		return architectureAarch64, gpu
+	}
+	return architectureX86, gpu
+}
+
+// shapeArchitecture is the architecture of a shape's processors
+func shapeArchitecture(shape core.Shape) (string, bool) {
+	gpu := shape.Gpus != nil && *shape.Gpus > 0
+	if (shape.ProcessorDescription != nil && strings.Contains(*shape.ProcessorDescription, "Ampere")) || ampereShapePattern.MatchString(*shape.Shape) {
+		return architectureAarch64, gpu
+	}
+	return architectureX86, gpu
+}
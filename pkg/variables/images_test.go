@@ -0,0 +1,107 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"context"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/stretchr/testify/assert"
+	ocifake "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci/fake"
+	"testing"
+)
+
+const (
+	testX86Image     = "Oracle-Linux-8.7-2023.05.24-0-OKE-1.26.2-625"
+	testArmImage     = "Oracle-Linux-8.7-aarch64-2023.05.24-0-OKE-1.26.2-625"
+	testGPUImage     = "Oracle-Linux-8.7-Gen2-GPU-2023.05.24-0-OKE-1.26.2-625"
+	testX86ImageId   = "ocid1.image.oc1.phx.x86"
+	testArmImageId   = "ocid1.image.oc1.phx.arm"
+	testGPUImageId   = "ocid1.image.oc1.phx.gpu"
+	testAmpereShape  = "VM.Standard.A1.Flex"
+	testGPUShape     = "VM.GPU3.1"
+	testStdFlexShape = "VM.Standard.E4.Flex"
+)
+
+func TestSetNodePoolImages(t *testing.T) {
+	shapes := map[string]core.Shape{
+		testStdFlexShape: {Shape: common.String(testStdFlexShape), ProcessorDescription: common.String("2.55 GHz AMD EPYC 7J13 (Milan)")},
+		testAmpereShape:  {Shape: common.String(testAmpereShape), ProcessorDescription: common.String("3.0 GHz Ampere Altra")},
+		testGPUShape:     {Shape: common.String(testGPUShape), Gpus: common.Int(1)},
+	}
+	client := &ocifake.Client{
+		Images: map[string]string{
+			testX86Image: testX86ImageId,
+			testArmImage: testArmImageId,
+			testGPUImage: testGPUImageId,
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		np       NodePool
+		res      string
+		hasError bool
+	}{
+		{
+			"cluster image",
+			NodePool{Shape: testStdFlexShape},
+			testX86ImageId,
+			false,
+		},
+		{
+			"image by display name",
+			NodePool{Shape: testAmpereShape, Image: testArmImage},
+			testArmImageId,
+			false,
+		},
+		{
+			"image by OCID",
+			NodePool{Shape: testGPUShape, Image: testGPUImageId},
+			testGPUImageId,
+			false,
+		},
+		{
+			"unknown image",
+			NodePool{Shape: testStdFlexShape, Image: "missing"},
+			"",
+			true,
+		},
+		{
+			"cluster image on an Ampere shape",
+			NodePool{Shape: testAmpereShape},
+			"",
+			true,
+		},
+		{
+			"GPU image on a CPU shape",
+			NodePool{Shape: testStdFlexShape, Image: testGPUImage},
+			"",
+			true,
+		},
+		{
+			"GPU shape without a GPU image",
+			NodePool{Shape: testGPUShape},
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{
+				ImageDisplayName: testX86Image,
+				ActualImage:      testX86ImageId,
+				NodePools:        []NodePool{tt.np},
+			}
+			err := v.setNodePoolImages(context.TODO(), client, shapes)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.res, v.NodePools[0].ActualImage)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+const (
+	DefaultVCNCIDR = "10.0.0.0/16"
+
+	// VCN prefixes are bounded so each subnet in the plan fits, and stays usable for a cluster
+	minVCNPrefix = 16
+	maxVCNPrefix = 22
+)
+
+// NetworkPlan is the addressing of a quick create VCN. The security rules of the VCN use the same CIDRs as its subnets.
+type NetworkPlan struct {
+	VCN                  string `json:"vcn"`
+	ControlPlaneEndpoint string `json:"controlPlaneEndpoint"`
+	ServiceLB            string `json:"serviceLb"`
+	Worker               string `json:"worker"`
+	Pod                  string `json:"pod"`
+}
+
+// PlanNetwork derives the subnets of a quick create VCN from the VCN CIDR.
+// The control plane endpoint and load balancer subnets share the first quarter of the VCN, workers get the second quarter
+// and pods get the second half. A 10.0.0.0/16 VCN uses 10.0.0.8/29, 10.0.0.32/27, 10.0.64.0/20 and 10.0.128.0/18.
+func PlanNetwork(vcnCIDR string) (*NetworkPlan, error) {
+	ip, vcn, err := net.ParseCIDR(vcnCIDR)
+	if err != nil || ip.To4() == nil {
+		return nil, fmt.Errorf("invalid VCN CIDR %s, must be an IPv4 CIDR block", vcnCIDR)
+	}
+	if !ip.Equal(vcn.IP) {
+		return nil, fmt.Errorf("invalid VCN CIDR %s, did you mean %s", vcnCIDR, vcn.String())
+	}
+	prefix, _ := vcn.Mask.Size()
+	if prefix < minVCNPrefix || prefix > maxVCNPrefix {
+		return nil, fmt.Errorf("invalid VCN CIDR %s, the prefix length must be between /%d and /%d", vcnCIDR, minVCNPrefix, maxVCNPrefix)
+	}
+	return &NetworkPlan{
+		VCN:                  vcn.String(),
+		ControlPlaneEndpoint: subnetCIDR(vcn, 29, 1),
+		ServiceLB:            subnetCIDR(vcn, 27, 1),
+		Worker:               subnetCIDR(vcn, prefix+4, 4),
+		Pod:                  subnetCIDR(vcn, prefix+2, 2),
+	}, nil
+}
+
+// subnetCIDR is the index'th subnet of size prefix in the network
+func subnetCIDR(network *net.IPNet, prefix, index int) string {
+	base := binary.BigEndian.Uint32(network.IP.To4())
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, base+uint32(index)<<(32-prefix))
+	return (&net.IPNet{IP: ip, Mask: net.CIDRMask(prefix, 32)}).String()
+}
+
+// setNetworkPlan plans the quick create VCN, which must not overlap the cluster's pod or service CIDRs
+func (v *Variables) setNetworkPlan() error {
+	if !v.QuickCreateVCN {
+		v.Network = nil
+		return nil
+	}
+	if v.VCNCIDR == "" {
+		v.VCNCIDR = DefaultVCNCIDR
+	}
+	plan, err := PlanNetwork(v.VCNCIDR)
+	if err != nil {
+		return err
+	}
+	_, vcn, _ := net.ParseCIDR(plan.VCN)
+	for _, cidr := range []struct {
+		name  string
+		value string
+	}{
+		{"pod", v.PodCIDR},
+		{"cluster", v.ClusterCIDR},
+	} {
+		if cidr.value == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(cidr.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s CIDR %s", cidr.name, cidr.value)
+		}
+		if vcn.Contains(n.IP) || n.Contains(vcn.IP) {
+			return fmt.Errorf("VCN CIDR %s overlaps the %s CIDR %s", plan.VCN, cidr.name, cidr.value)
+		}
+	}
+	v.Network = plan
+	return nil
+}
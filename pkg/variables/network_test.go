@@ -0,0 +1,129 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlanNetwork(t *testing.T) {
+	var tests = []struct {
+		name     string
+		vcnCIDR  string
+		plan     *NetworkPlan
+		hasError bool
+	}{
+		{
+			"default VCN",
+			DefaultVCNCIDR,
+			&NetworkPlan{
+				VCN:                  "10.0.0.0/16",
+				ControlPlaneEndpoint: "10.0.0.8/29",
+				ServiceLB:            "10.0.0.32/27",
+				Worker:               "10.0.64.0/20",
+				Pod:                  "10.0.128.0/18",
+			},
+			false,
+		},
+		{
+			"smallest VCN",
+			"172.16.4.0/22",
+			&NetworkPlan{
+				VCN:                  "172.16.4.0/22",
+				ControlPlaneEndpoint: "172.16.4.8/29",
+				ServiceLB:            "172.16.4.32/27",
+				Worker:               "172.16.5.0/26",
+				Pod:                  "172.16.6.0/24",
+			},
+			false,
+		},
+		{
+			"too small",
+			"10.0.0.0/24",
+			nil,
+			true,
+		},
+		{
+			"too large",
+			"10.0.0.0/8",
+			nil,
+			true,
+		},
+		{
+			"host bits set",
+			"10.0.0.1/16",
+			nil,
+			true,
+		},
+		{
+			"IPv6",
+			"fd00::/48",
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanNetwork(tt.vcnCIDR)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.plan, plan)
+		})
+	}
+}
+
+func TestSetNetworkPlan(t *testing.T) {
+	var tests = []struct {
+		name        string
+		vcnCIDR     string
+		podCIDR     string
+		clusterCIDR string
+		hasError    bool
+	}{
+		{
+			"defaults",
+			"",
+			"10.244.0.0/16",
+			"10.96.0.0/16",
+			false,
+		},
+		{
+			"overlaps pod CIDR",
+			"10.244.0.0/20",
+			"10.244.0.0/16",
+			"10.96.0.0/16",
+			true,
+		},
+		{
+			"overlaps cluster CIDR",
+			"10.0.0.0/16",
+			"10.244.0.0/16",
+			"10.0.0.0/12",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{
+				QuickCreateVCN: true,
+				VCNCIDR:        tt.vcnCIDR,
+				PodCIDR:        tt.podCIDR,
+				ClusterCIDR:    tt.clusterCIDR,
+			}
+			err := v.setNetworkPlan()
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, DefaultVCNCIDR, v.Network.VCN)
+		})
+	}
+}
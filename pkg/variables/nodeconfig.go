@@ -0,0 +1,153 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"encoding/base64"
+	"fmt"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sort"
+	"strings"
+)
+
+const (
+	TaintEffectNoSchedule       = "NoSchedule"
+	TaintEffectPreferNoSchedule = "PreferNoSchedule"
+	TaintEffectNoExecute        = "NoExecute"
+
+	CapacityTypeOnDemand    = "on-demand"
+	CapacityTypePreemptible = "preemptible"
+	CapacityTypeReserved    = "reserved"
+
+	PreemptionActionTerminate                   = "TERMINATE"
+	PreemptionActionTerminatePreserveBootVolume = "TERMINATE_PRESERVE_BOOT_VOLUME"
+
+	// okeInitScript runs the OKE node bootstrap from the instance metadata, passing extra kubelet arguments
+	okeInitScript = `#!/bin/bash
+curl --fail -H "Authorization: Bearer Oracle" -L0 http://169.254.169.254/opc/v2/instance/metadata/oke_init_script | base64 --decode >/var/run/oke-init.sh
+bash /var/run/oke-init.sh --kubelet-extra-args "%s"
+`
+)
+
+var (
+	taintEffects      = []string{TaintEffectNoSchedule, TaintEffectPreferNoSchedule, TaintEffectNoExecute}
+	capacityTypes     = []string{CapacityTypeOnDemand, CapacityTypePreemptible, CapacityTypeReserved}
+	preemptionActions = []string{PreemptionActionTerminate, PreemptionActionTerminatePreserveBootVolume}
+)
+
+// Taint is a Kubernetes node taint
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+func (t Taint) String() string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+// UserData is the base64 encoded cloud-init script that registers the pool's nodes with their taints.
+// OKE has no node pool taints, so the taints are passed to the kubelet when the node bootstraps.
+func (np NodePool) UserData() string {
+	if len(np.Taints) < 1 {
+		return ""
+	}
+	var taints []string
+	for _, taint := range np.Taints {
+		taints = append(taints, taint.String())
+	}
+	script := fmt.Sprintf(okeInitScript, "--register-with-taints="+strings.Join(taints, ","))
+	return base64.StdEncoding.EncodeToString([]byte(script))
+}
+
+// Preemptible is true if the pool's nodes are preemptible instances
+func (np NodePool) Preemptible() bool {
+	return np.CapacityType == CapacityTypePreemptible
+}
+
+// PreserveBootVolume is true if the boot volumes of preempted nodes are kept
+func (np NodePool) PreserveBootVolume() bool {
+	return np.PreemptionAction == PreemptionActionTerminatePreserveBootVolume
+}
+
+// PreemptibleNodePools are the names of the cluster's preemptible node pools
+func (v *Variables) PreemptibleNodePools() []string {
+	var names []string
+	for _, np := range v.NodePools {
+		if np.Preemptible() {
+			names = append(names, np.Name)
+		}
+	}
+	return names
+}
+
+func validateCapacity(path *field.Path, np NodePool) field.ErrorList {
+	var errs field.ErrorList
+	if np.CapacityType != "" && !contains(capacityTypes, np.CapacityType) {
+		errs = append(errs, field.NotSupported(path.Child("capacityType"), np.CapacityType, capacityTypes))
+	}
+	if np.PreemptionAction != "" {
+		if !np.Preemptible() {
+			errs = append(errs, field.Forbidden(path.Child("preemptionAction"), "only preemptible node pools have a preemption action"))
+		} else if !contains(preemptionActions, np.PreemptionAction) {
+			errs = append(errs, field.NotSupported(path.Child("preemptionAction"), np.PreemptionAction, preemptionActions))
+		}
+	}
+	if np.CapacityType == CapacityTypeReserved {
+		errs = append(errs, validateOCID(path.Child("capacityReservationId"), np.CapacityReservationID, true, "capacityreservation")...)
+		// capacity reservations belong to a single availability domain
+		if len(np.AvailabilityDomains) != 1 {
+			errs = append(errs, field.Invalid(path.Child("availabilityDomains"), np.AvailabilityDomains, "reserved node pools must be placed in the capacity reservation's availability domain"))
+		}
+	} else if np.CapacityReservationID != "" {
+		errs = append(errs, field.Forbidden(path.Child("capacityReservationId"), "only reserved node pools use a capacity reservation"))
+	}
+	return errs
+}
+
+func validateNodeLabelsAndTaints(path *field.Path, np NodePool) field.ErrorList {
+	var errs field.ErrorList
+	var keys []string
+	for key := range np.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := np.Labels[key]
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(path.Child("labels"), key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, field.Invalid(path.Child("labels").Key(key), value, msg))
+		}
+	}
+	for i, taint := range np.Taints {
+		taintPath := path.Child("taints").Index(i)
+		for _, msg := range validation.IsQualifiedName(taint.Key) {
+			errs = append(errs, field.Invalid(taintPath.Child("key"), taint.Key, msg))
+		}
+		if taint.Value != "" {
+			for _, msg := range validation.IsValidLabelValue(taint.Value) {
+				errs = append(errs, field.Invalid(taintPath.Child("value"), taint.Value, msg))
+			}
+		}
+		if !contains(taintEffects, taint.Effect) {
+			errs = append(errs, field.NotSupported(taintPath.Child("effect"), taint.Effect, taintEffects))
+		}
+	}
+	return errs
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
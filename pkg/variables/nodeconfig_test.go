@@ -0,0 +1,48 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNodePoolUserData(t *testing.T) {
+	np := NodePool{Name: "np-1"}
+	assert.Empty(t, np.UserData())
+
+	np.Taints = []Taint{
+		{Key: "nvidia.com/gpu", Value: "present", Effect: TaintEffectNoSchedule},
+		{Key: "batch", Effect: TaintEffectNoExecute},
+	}
+	script, err := base64.StdEncoding.DecodeString(np.UserData())
+	assert.NoError(t, err)
+	assert.Contains(t, string(script), `--kubelet-extra-args "--register-with-taints=nvidia.com/gpu=present:NoSchedule,batch:NoExecute"`)
+}
+
+func TestParseNodeLabelsAndTaints(t *testing.T) {
+	v := &Variables{
+		RawNodePools: []string{
+			`{"name":"gpu","replicas":1,"labels":{"workload":"gpu"},"taints":[{"key":"nvidia.com/gpu","effect":"NoSchedule"}]}`,
+		},
+	}
+	nps, err := v.ParseNodePools()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"workload": "gpu"}, nps[0].Labels)
+	assert.Equal(t, []Taint{{Key: "nvidia.com/gpu", Effect: TaintEffectNoSchedule}}, nps[0].Taints)
+}
+
+func TestPreemptibleNodePools(t *testing.T) {
+	v := &Variables{
+		NodePools: []NodePool{
+			{Name: "stable"},
+			{Name: "ci", CapacityType: CapacityTypePreemptible, PreemptionAction: PreemptionActionTerminatePreserveBootVolume},
+			{Name: "batch", CapacityType: CapacityTypePreemptible},
+		},
+	}
+	assert.Equal(t, []string{"ci", "batch"}, v.PreemptibleNodePools())
+	assert.True(t, v.NodePools[1].PreserveBootVolume())
+	assert.False(t, v.NodePools[2].PreserveBootVolume())
+}
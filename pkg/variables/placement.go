@@ -0,0 +1,100 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"context"
+	"fmt"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci"
+	"strings"
+)
+
+const faultDomainPrefix = "FAULT-DOMAIN-"
+
+// setAvailabilityDomains discovers the region's availability domains, and resolves each node pool's availability domains to their full names
+func (v *Variables) setAvailabilityDomains(ctx context.Context, client oci.Client) error {
+	ads, err := client.GetAvailabilityDomains(ctx, v.CompartmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get availability domains: %v", err)
+	}
+	v.AvailabilityDomains = ads
+	for i, np := range v.NodePools {
+		for j, ad := range np.AvailabilityDomains {
+			name, err := resolveAvailabilityDomain(ads, ad)
+			if err != nil {
+				return fmt.Errorf("node pool %s: %v", np.Name, err)
+			}
+			v.NodePools[i].AvailabilityDomains[j] = name
+		}
+	}
+	return nil
+}
+
+// resolveAvailabilityDomain finds an availability domain by its full name, e.g. Uocm:PHX-AD-1, or by its suffix, e.g. AD-1
+func resolveAvailabilityDomain(ads []string, ad string) (string, error) {
+	for _, name := range ads {
+		if strings.EqualFold(name, ad) || strings.HasSuffix(strings.ToUpper(name), "-"+strings.ToUpper(ad)) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown availability domain %s, must be one of %s", ad, strings.Join(ads, ", "))
+}
+
+// setNetworkSecurityGroups collects the node pools' network security groups, so they can be referenced by name
+func (v *Variables) setNetworkSecurityGroups() {
+	var nsgs []NetworkSecurityGroup
+	for _, np := range v.NodePools {
+		for _, id := range np.NSGs {
+			if nsgName(nsgs, id) != "" {
+				continue
+			}
+			nsgs = append(nsgs, NetworkSecurityGroup{
+				Id:   id,
+				Role: workerSubnetRole,
+				Name: fmt.Sprintf("%s-nsg-%d", workerSubnetRole, len(nsgs)+1),
+			})
+		}
+	}
+	v.NetworkSecurityGroups = nsgs
+}
+
+func nsgName(nsgs []NetworkSecurityGroup, id string) string {
+	for _, nsg := range nsgs {
+		if nsg.Id == id {
+			return nsg.Name
+		}
+	}
+	return ""
+}
+
+// WorkerSubnetName is the name of a node pool's worker subnet in the cluster's network spec
+func (v Variables) WorkerSubnetName(subnetId string) string {
+	if subnetId != "" {
+		for _, subnet := range v.Subnets {
+			if subnet.Id == subnetId {
+				return subnet.Name
+			}
+		}
+	}
+	return workerSubnetRole
+}
+
+// NSGNames are the names of a node pool's network security groups in the cluster's network spec
+func (v Variables) NSGNames(ids []string) []string {
+	var names []string
+	for _, id := range ids {
+		if name := nsgName(v.NetworkSecurityGroups, id); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// PlacementAvailabilityDomains are the availability domains a node pool is placed in
+func (v Variables) PlacementAvailabilityDomains(np NodePool) []string {
+	if len(np.AvailabilityDomains) > 0 {
+		return np.AvailabilityDomains
+	}
+	return v.AvailabilityDomains
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"context"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/stretchr/testify/assert"
+	ocifake "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci/fake"
+	"testing"
+)
+
+const (
+	testWorkerSubnet = "ocid1.subnet.oc1.phx.worker"
+	testPoolSubnet   = "ocid1.subnet.oc1.phx.pool"
+	testNSG          = "ocid1.networksecuritygroup.oc1.phx.nsg"
+)
+
+var testADs = []string{"Uocm:PHX-AD-1", "Uocm:PHX-AD-2", "Uocm:PHX-AD-3"}
+
+func TestSetAvailabilityDomains(t *testing.T) {
+	var tests = []struct {
+		name     string
+		ads      []string
+		res      []string
+		hasError bool
+	}{
+		{
+			"spread across the region",
+			nil,
+			nil,
+			false,
+		},
+		{
+			"full names and suffixes",
+			[]string{"Uocm:PHX-AD-1", "ad-3"},
+			[]string{"Uocm:PHX-AD-1", "Uocm:PHX-AD-3"},
+			false,
+		},
+		{
+			"unknown availability domain",
+			[]string{"AD-4"},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{
+				NodePools: []NodePool{{Name: "np-1", AvailabilityDomains: tt.ads}},
+			}
+			err := v.setAvailabilityDomains(context.TODO(), &ocifake.Client{AvailabilityDomains: testADs})
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testADs, v.AvailabilityDomains)
+			assert.Equal(t, tt.res, v.NodePools[0].AvailabilityDomains)
+			if tt.res == nil {
+				assert.Equal(t, testADs, v.PlacementAvailabilityDomains(v.NodePools[0]))
+			} else {
+				assert.Equal(t, tt.res, v.PlacementAvailabilityDomains(v.NodePools[0]))
+			}
+		})
+	}
+}
+
+func TestNodePoolSubnetsAndNSGs(t *testing.T) {
+	client := &ocifake.Client{
+		Subnets: map[string]*core.Subnet{
+			testWorkerSubnet: {CidrBlock: common.String("10.0.64.0/20")},
+			testPoolSubnet:   {CidrBlock: common.String("10.0.80.0/20")},
+		},
+	}
+	v := &Variables{
+		WorkerNodeSubnet: testWorkerSubnet,
+		NodePools: []NodePool{
+			{Name: "np-1"},
+			{Name: "np-2", Subnet: testWorkerSubnet},
+			{Name: "np-3", Subnet: testPoolSubnet, NSGs: []string{testNSG}},
+			{Name: "np-4", Subnet: testPoolSubnet, NSGs: []string{testNSG}},
+		},
+	}
+	assert.NoError(t, v.setSubnets(context.TODO(), client))
+	v.setNetworkSecurityGroups()
+
+	assert.Len(t, v.Subnets, 2)
+	assert.Equal(t, "worker", v.WorkerSubnetName(v.NodePools[0].Subnet))
+	assert.Equal(t, "worker", v.WorkerSubnetName(v.NodePools[1].Subnet))
+	assert.Equal(t, "worker-np-3", v.WorkerSubnetName(v.NodePools[2].Subnet))
+	assert.Equal(t, "worker-np-3", v.WorkerSubnetName(v.NodePools[3].Subnet))
+
+	assert.Len(t, v.NetworkSecurityGroups, 1)
+	assert.Equal(t, []string{"worker-nsg-1"}, v.NSGNames(v.NodePools[3].NSGs))
+	assert.Nil(t, v.NSGNames(v.NodePools[0].NSGs))
+}
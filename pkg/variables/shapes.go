@@ -0,0 +1,112 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"context"
+	"fmt"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	driverconst "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/constants"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"strings"
+)
+
+const flexShapeSuffix = ".Flex"
+
+// Flexible is true if the pool's shape is a flexible shape, sized by OCPUs and memory
+func (np NodePool) Flexible() bool {
+	return strings.HasSuffix(np.Shape, flexShapeSuffix)
+}
+
+// applyNodePoolDefaults fills in the fields a node pool doesn't set.
+// Fixed shapes have a fixed size, so their OCPUs and memory are dropped.
+func applyNodePoolDefaults(np *NodePool) {
+	if np.Shape == "" {
+		np.Shape = DefaultVMShape
+	}
+	if np.VolumeSize == 0 {
+		np.VolumeSize = DefaultVolumeGbs
+	}
+	if !np.Flexible() {
+		np.Ocpus = 0
+		np.Memory = 0
+		return
+	}
+	if np.Ocpus == 0 {
+		np.Ocpus = DefaultOCICPUs
+	}
+	if np.Memory == 0 {
+		np.Memory = DefaultMemoryGbs
+	}
+}
+
+// getShapes gets the compute shapes available in the cluster's compartment, by name
+func (v *Variables) getShapes(ctx context.Context, client oci.Client) (map[string]core.Shape, error) {
+	shapes, err := client.GetShapes(ctx, v.CompartmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute shapes: %v", err)
+	}
+	catalogue := map[string]core.Shape{}
+	for _, shape := range shapes {
+		catalogue[*shape.Shape] = shape
+	}
+	return catalogue, nil
+}
+
+// validateShapes checks each node pool's shape is available, and that flexible shapes are sized within the shape's limits
+func (v *Variables) validateShapes(catalogue map[string]core.Shape) error {
+	var errs field.ErrorList
+	path := field.NewPath(driverconst.RawNodePools)
+	for i, np := range v.NodePools {
+		shape, ok := catalogue[np.Shape]
+		if !ok {
+			errs = append(errs, field.Invalid(path.Index(i).Child("shape"), np.Shape, "shape is not available in the compartment"))
+			continue
+		}
+		if np.Flexible() {
+			errs = append(errs, validateShapeConfig(path.Index(i), np, shape)...)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid node pool shapes: %v", errs.ToAggregate())
+	}
+	return nil
+}
+
+func validateShapeConfig(path *field.Path, np NodePool, shape core.Shape) field.ErrorList {
+	var errs field.ErrorList
+	ocpus := float32(np.Ocpus)
+	memory := float32(np.Memory)
+	if o := shape.OcpuOptions; o != nil {
+		if !inRange(ocpus, o.Min, o.Max) {
+			errs = append(errs, field.Invalid(path.Child("ocpus"), np.Ocpus, fmt.Sprintf("%s supports %s OCPUs", np.Shape, describeRange(o.Min, o.Max))))
+		}
+	}
+	if m := shape.MemoryOptions; m != nil {
+		if !inRange(memory, m.MinInGBs, m.MaxInGBs) {
+			errs = append(errs, field.Invalid(path.Child("memory"), np.Memory, fmt.Sprintf("%s supports %s GBs of memory", np.Shape, describeRange(m.MinInGBs, m.MaxInGBs))))
+		} else if ocpus > 0 && !inRange(memory/ocpus, m.MinPerOcpuInGBs, m.MaxPerOcpuInGBs) {
+			errs = append(errs, field.Invalid(path.Child("memory"), np.Memory, fmt.Sprintf("%s supports %s GBs of memory per OCPU", np.Shape, describeRange(m.MinPerOcpuInGBs, m.MaxPerOcpuInGBs))))
+		}
+	}
+	return errs
+}
+
+func inRange(value float32, min, max *float32) bool {
+	return (min == nil || value >= *min) && (max == nil || value <= *max)
+}
+
+func describeRange(min, max *float32) string {
+	switch {
+	case min != nil && max != nil:
+		return fmt.Sprintf("%g to %g", *min, *max)
+	case min != nil:
+		return fmt.Sprintf("at least %g", *min)
+	case max != nil:
+		return fmt.Sprintf("at most %g", *max)
+	default:
+		return "any number of"
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"context"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/stretchr/testify/assert"
+	ocifake "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci/fake"
+	"testing"
+)
+
+var testShapes = []core.Shape{
+	{
+		Shape: common.String("VM.Standard.E4.Flex"),
+		OcpuOptions: &core.ShapeOcpuOptions{
+			Min: common.Float32(1),
+			Max: common.Float32(64),
+		},
+		MemoryOptions: &core.ShapeMemoryOptions{
+			MinInGBs:        common.Float32(1),
+			MaxInGBs:        common.Float32(1024),
+			MinPerOcpuInGBs: common.Float32(1),
+			MaxPerOcpuInGBs: common.Float32(64),
+		},
+	},
+	{
+		Shape: common.String("VM.Standard2.1"),
+	},
+}
+
+func TestApplyNodePoolDefaults(t *testing.T) {
+	var tests = []struct {
+		name string
+		np   NodePool
+		res  NodePool
+	}{
+		{
+			"defaults",
+			NodePool{Name: "np"},
+			NodePool{Name: "np", Shape: DefaultVMShape, Ocpus: DefaultOCICPUs, Memory: DefaultMemoryGbs, VolumeSize: DefaultVolumeGbs},
+		},
+		{
+			"sized flex shape",
+			NodePool{Shape: "VM.Standard.E4.Flex", Ocpus: 4, Memory: 64, VolumeSize: 50},
+			NodePool{Shape: "VM.Standard.E4.Flex", Ocpus: 4, Memory: 64, VolumeSize: 50},
+		},
+		{
+			"fixed shape drops sizing",
+			NodePool{Shape: "VM.Standard2.1", Ocpus: 4, Memory: 64},
+			NodePool{Shape: "VM.Standard2.1", VolumeSize: DefaultVolumeGbs},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			np := tt.np
+			applyNodePoolDefaults(&np)
+			assert.Equal(t, tt.res, np)
+		})
+	}
+}
+
+func TestValidateShapes(t *testing.T) {
+	var tests = []struct {
+		name     string
+		np       NodePool
+		hasError bool
+	}{
+		{
+			"flex shape in range",
+			NodePool{Shape: "VM.Standard.E4.Flex", Ocpus: 2, Memory: 16},
+			false,
+		},
+		{
+			"fixed shape",
+			NodePool{Shape: "VM.Standard2.1"},
+			false,
+		},
+		{
+			"unknown shape",
+			NodePool{Shape: "VM.Unknown"},
+			true,
+		},
+		{
+			"too many OCPUs",
+			NodePool{Shape: "VM.Standard.E4.Flex", Ocpus: 128, Memory: 256},
+			true,
+		},
+		{
+			"too much memory per OCPU",
+			NodePool{Shape: "VM.Standard.E4.Flex", Ocpus: 1, Memory: 128},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{NodePools: []NodePool{tt.np}}
+			shapes, err := v.getShapes(context.TODO(), &ocifake.Client{Shapes: testShapes})
+			assert.NoError(t, err)
+			err = v.validateShapes(shapes)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"encoding/json"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/version"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sort"
+	"strings"
+)
+
+// Every OCI resource of the cluster is tagged with the Rancher cluster and the driver version
+const (
+	RancherClusterIDTag   = "rancher-cluster-id"
+	RancherClusterNameTag = "rancher-cluster-name"
+	// DriverVersionTag is the version of the driver that created the cluster. It is kept when newer drivers update the cluster.
+	DriverVersionTag = "oke-capi-driver-version"
+
+	maxTagKeyLength = 100
+)
+
+// Tags are the freeform and defined tags of an OCI resource
+type Tags struct {
+	FreeformTags map[string]string            `json:"freeformTags,omitempty"`
+	DefinedTags  map[string]map[string]string `json:"definedTags,omitempty"`
+}
+
+// Empty is true if there are no tags
+func (t Tags) Empty() bool {
+	return len(t.FreeformTags) == 0 && len(t.DefinedTags) == 0
+}
+
+// parseTags deserializes the cluster's freeform and defined tags
+func (v *Variables) parseTags(freeformPath, definedPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	v.FreeformTags = nil
+	if v.RawFreeformTags != "" {
+		if err := json.Unmarshal([]byte(v.RawFreeformTags), &v.FreeformTags); err != nil {
+			v.FreeformTags = nil
+			errs = append(errs, field.Invalid(freeformPath, v.RawFreeformTags, err.Error()))
+		}
+	}
+	v.DefinedTags = nil
+	if v.RawDefinedTags != "" {
+		if err := json.Unmarshal([]byte(v.RawDefinedTags), &v.DefinedTags); err != nil {
+			v.DefinedTags = nil
+			errs = append(errs, field.Invalid(definedPath, v.RawDefinedTags, err.Error()))
+		}
+	}
+	return errs
+}
+
+// ClusterFreeformTags are the cluster's freeform tags, and the tags identifying the Rancher cluster and driver version
+func (v Variables) ClusterFreeformTags() map[string]string {
+	tags := map[string]string{}
+	for key, value := range v.FreeformTags {
+		tags[key] = value
+	}
+	tags[RancherClusterIDTag] = v.Name
+	tags[RancherClusterNameTag] = v.DisplayName
+	tags[DriverVersionTag] = version.DriverVersion
+	return tags
+}
+
+// Tags are the cluster's tags, as tagged on each OCI resource
+func (v Variables) Tags() Tags {
+	return Tags{
+		FreeformTags: v.ClusterFreeformTags(),
+		DefinedTags:  v.DefinedTags,
+	}
+}
+
+// Tags are the tags that override the cluster's tags on the pool
+func (np NodePool) Tags() Tags {
+	return Tags{
+		FreeformTags: np.FreeformTags,
+		DefinedTags:  np.DefinedTags,
+	}
+}
+
+// HasTags is true if the pool overrides the cluster's tags
+func (np NodePool) HasTags() bool {
+	return !np.Tags().Empty()
+}
+
+// HasNodePoolTags is true if any node pool overrides the cluster's tags, or the driver has tags to remove from a node pool
+func (v *Variables) HasNodePoolTags() bool {
+	for _, np := range v.NodePools {
+		if np.HasTags() {
+			return true
+		}
+	}
+	return len(v.NodePoolTags) > 0
+}
+
+func validateTags(freeformPath, definedPath *field.Path, freeformTags map[string]string, definedTags map[string]map[string]string) field.ErrorList {
+	var errs field.ErrorList
+	for _, key := range sortedKeys(freeformTags) {
+		errs = append(errs, validateTagKey(freeformPath, key)...)
+		switch key {
+		case RancherClusterIDTag, RancherClusterNameTag, DriverVersionTag:
+			errs = append(errs, field.Forbidden(freeformPath.Key(key), "tag is set by the driver"))
+		}
+	}
+	var namespaces []string
+	for namespace := range definedTags {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			errs = append(errs, field.Required(definedPath, "defined tags must have a tag namespace"))
+		}
+		for _, key := range sortedKeys(definedTags[namespace]) {
+			errs = append(errs, validateTagKey(definedPath.Key(namespace), key)...)
+		}
+	}
+	return errs
+}
+
+func validateTagKey(path *field.Path, key string) field.ErrorList {
+	switch {
+	case key == "":
+		return field.ErrorList{field.Required(path, "tags must have a key")}
+	case len(key) > maxTagKeyLength:
+		return field.ErrorList{field.TooLong(path.Key(key), key, maxTagKeyLength)}
+	case strings.ContainsAny(key, " ."):
+		return field.ErrorList{field.Invalid(path.Key(key), key, "tag keys must not contain spaces or periods")}
+	}
+	return nil
+}
+
+func sortedKeys(tags map[string]string) []string {
+	var keys []string
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
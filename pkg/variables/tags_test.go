@@ -0,0 +1,80 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/version"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	var tests = []struct {
+		name         string
+		freeformTags string
+		definedTags  string
+		freeform     map[string]string
+		defined      map[string]map[string]string
+		hasError     bool
+	}{
+		{
+			"no tags",
+			"",
+			"",
+			nil,
+			nil,
+			false,
+		},
+		{
+			"freeform and defined tags",
+			`{"team":"platform"}`,
+			`{"Operations":{"CostCenter":"42"}}`,
+			map[string]string{"team": "platform"},
+			map[string]map[string]string{"Operations": {"CostCenter": "42"}},
+			false,
+		},
+		{
+			"defined tags without a namespace",
+			"",
+			`{"CostCenter":"42"}`,
+			nil,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{
+				RawFreeformTags: tt.freeformTags,
+				RawDefinedTags:  tt.definedTags,
+			}
+			errs := v.parseTags(field.NewPath("freeform-tags"), field.NewPath("defined-tags"))
+			if tt.hasError {
+				assert.NotEmpty(t, errs)
+				return
+			}
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.freeform, v.FreeformTags)
+			assert.Equal(t, tt.defined, v.DefinedTags)
+		})
+	}
+}
+
+func TestClusterFreeformTags(t *testing.T) {
+	v := Variables{
+		Name:         "c-abcde",
+		DisplayName:  "my-cluster",
+		FreeformTags: map[string]string{"team": "platform"},
+	}
+	assert.Equal(t, map[string]string{
+		"team":                "platform",
+		RancherClusterIDTag:   "c-abcde",
+		RancherClusterNameTag: "my-cluster",
+		DriverVersionTag:      version.DriverVersion,
+	}, v.ClusterFreeformTags())
+	// the cluster's tags are not modified
+	assert.Len(t, v.FreeformTags, 1)
+}
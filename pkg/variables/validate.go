@@ -0,0 +1,225 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"fmt"
+	driverconst "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/constants"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ocidPattern matches ocid1.<resource type>.<realm>.[region][.future use].<unique id>
+var ocidPattern = regexp.MustCompile(`^ocid1\.([a-z0-9]+)\.[a-z0-9]+\.[a-z0-9-]*(\.[a-z0-9-]*)?\.[a-z0-9]+$`)
+
+// Validate checks the cluster options before any objects are written, and returns every problem found.
+// Field paths are the driver option names, so errors can be traced back to the cluster's configuration.
+func (v *Variables) Validate() field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Label(v.Name) {
+		errs = append(errs, field.Invalid(field.NewPath(driverconst.ClusterName), v.Name, msg))
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(v.DisplayName) {
+		errs = append(errs, field.Invalid(field.NewPath(driverconst.DisplayName), v.DisplayName, msg))
+	}
+
+	errs = append(errs, validateOCID(field.NewPath(driverconst.CompartmentID), v.CompartmentID, true, "compartment", "tenancy")...)
+	errs = append(errs, validateOCID(field.NewPath(driverconst.ImageId), v.ImageID, false, "image")...)
+	errs = append(errs, validateOCID(field.NewPath(driverconst.KMSKeyID), v.KMSKeyID, false, "key")...)
+	if v.ImageVerification && len(v.ImageVerificationKMSKeyIDs) == 0 {
+		errs = append(errs, field.Required(field.NewPath(driverconst.ImageVerificationKMSKeyIDs), "image verification requires at least one KMS key"))
+	}
+	for i, id := range v.ImageVerificationKMSKeyIDs {
+		errs = append(errs, validateOCID(field.NewPath(driverconst.ImageVerificationKMSKeyIDs).Index(i), id, true, "key")...)
+	}
+
+	// networking
+	errs = append(errs, validateCIDR(field.NewPath(driverconst.PodCIDR), v.PodCIDR)...)
+	errs = append(errs, validateCIDR(field.NewPath(driverconst.ClusterCIDR), v.ClusterCIDR)...)
+	if v.QuickCreateVCN {
+		if v.VCNCIDR != "" {
+			if _, err := PlanNetwork(v.VCNCIDR); err != nil {
+				errs = append(errs, field.Invalid(field.NewPath(driverconst.VCNCIDR), v.VCNCIDR, err.Error()))
+			}
+		}
+	} else {
+		errs = append(errs, validateOCID(field.NewPath(driverconst.VcnID), v.VCNID, true, "vcn")...)
+		errs = append(errs, validateOCID(field.NewPath(driverconst.WorkerNodeSubnet), v.WorkerNodeSubnet, true, "subnet")...)
+		errs = append(errs, validateOCID(field.NewPath(driverconst.ControlPlaneSubnet), v.ControlPlaneSubnet, true, "subnet")...)
+		errs = append(errs, validateOCID(field.NewPath(driverconst.LoadBalancerSubnet), v.LoadBalancerSubnet, false, "subnet")...)
+		errs = append(errs, validateOCID(field.NewPath(driverconst.PodSubnet), v.PodSubnet, false, "subnet")...)
+	}
+
+	// snapshots
+	switch v.SnapshotBackend {
+	case "", SnapshotBackendFilesystem:
+	case SnapshotBackendObjectStorage:
+		if v.SnapshotBucket == "" {
+			errs = append(errs, field.Required(field.NewPath(driverconst.SnapshotBucket), "the objectstorage snapshot backend requires a bucket"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(field.NewPath(driverconst.SnapshotBackend), v.SnapshotBackend, []string{SnapshotBackendObjectStorage, SnapshotBackendFilesystem}))
+	}
+
+	// node cycling defaults
+	errs = append(errs, validateNodeCount(field.NewPath(driverconst.NodeCyclingMaxSurge), v.NodeCyclingMaxSurge)...)
+	errs = append(errs, validateNodeCount(field.NewPath(driverconst.NodeCyclingMaxUnavailable), v.NodeCyclingMaxUnavailable)...)
+	errs = append(errs, validateEvictionGraceDuration(field.NewPath(driverconst.NodeEvictionGraceDuration), v.NodeEvictionGraceDuration)...)
+	if v.NodePoolRolloutTimeout != "" {
+		if timeout, err := time.ParseDuration(v.NodePoolRolloutTimeout); err != nil || timeout <= 0 {
+			errs = append(errs, field.Invalid(field.NewPath(driverconst.NodePoolRolloutTimeout), v.NodePoolRolloutTimeout, "must be a positive duration, e.g. 45m"))
+		}
+	}
+
+	errs = append(errs, v.parseErrors...)
+	errs = append(errs, validateNodePools(field.NewPath(driverconst.RawNodePools), v)...)
+	errs = append(errs, validateAddons(field.NewPath(driverconst.RawAddons), v)...)
+	errs = append(errs, validateTags(field.NewPath(driverconst.RawFreeformTags), field.NewPath(driverconst.RawDefinedTags), v.FreeformTags, v.DefinedTags)...)
+	return errs
+}
+
+func validateNodePools(path *field.Path, v *Variables) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{}
+	for i, np := range v.NodePools {
+		npPath := path.Index(i)
+		if np.Name == "" {
+			errs = append(errs, field.Required(npPath.Child("name"), "node pools must be named"))
+		} else {
+			for _, msg := range validation.IsDNS1123Label(np.Name) {
+				errs = append(errs, field.Invalid(npPath.Child("name"), np.Name, msg))
+			}
+			if names[np.Name] {
+				errs = append(errs, field.Duplicate(npPath.Child("name"), np.Name))
+			}
+			names[np.Name] = true
+		}
+		if np.Replicas < 0 {
+			errs = append(errs, field.Invalid(npPath.Child("replicas"), np.Replicas, "must not be negative"))
+		}
+		if np.Shape == "" {
+			errs = append(errs, field.Required(npPath.Child("shape"), "node pools must have a shape"))
+		}
+		if np.Flexible() && np.Ocpus < 1 {
+			errs = append(errs, field.Invalid(npPath.Child("ocpus"), np.Ocpus, fmt.Sprintf("%s is a flexible shape and requires at least 1 OCPU", np.Shape)))
+		}
+		if strings.HasPrefix(np.Image, "ocid1.") {
+			errs = append(errs, validateOCID(npPath.Child("image"), np.Image, false, "image")...)
+		}
+		errs = append(errs, validateOCID(npPath.Child("bootVolumeKmsKeyId"), np.BootVolumeKMSKeyID, false, "key")...)
+		if np.VolumeSize < 0 {
+			errs = append(errs, field.Invalid(npPath.Child("volumeSize"), np.VolumeSize, "must not be negative"))
+		}
+		errs = append(errs, validateNodePoolSize(npPath, np)...)
+		errs = append(errs, validatePlacement(npPath, np, v.QuickCreateVCN)...)
+		errs = append(errs, validateNodeLabelsAndTaints(npPath, np)...)
+		errs = append(errs, validateCapacity(npPath, np)...)
+		errs = append(errs, validateNodeCycling(npPath, np, v.NodeCycling(np))...)
+		errs = append(errs, validateTags(npPath.Child("freeformTags"), npPath.Child("definedTags"), np.FreeformTags, np.DefinedTags)...)
+	}
+	return errs
+}
+
+// validateNodePoolSize checks the pool has a single set of bounds. Autoscaled pools are bounded by minReplicas and maxReplicas,
+// and other pools by minSize and maxSize when the cluster is resized.
+func validateNodePoolSize(path *field.Path, np NodePool) field.ErrorList {
+	var errs field.ErrorList
+	if np.MinReplicas < 0 {
+		errs = append(errs, field.Invalid(path.Child("minReplicas"), np.MinReplicas, "must not be negative"))
+	}
+	if np.MaxReplicas < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxReplicas"), np.MaxReplicas, "must not be negative"))
+	}
+	if np.MinReplicas > 0 && !np.Autoscaled() {
+		errs = append(errs, field.Required(path.Child("maxReplicas"), "autoscaled node pools require maxReplicas"))
+	}
+	if np.Autoscaled() {
+		if np.MinReplicas > np.MaxReplicas {
+			errs = append(errs, field.Invalid(path.Child("minReplicas"), np.MinReplicas, fmt.Sprintf("must not be greater than maxReplicas %d", np.MaxReplicas)))
+		}
+		if np.MinSize != 0 {
+			errs = append(errs, field.Forbidden(path.Child("minSize"), "autoscaled node pools are bounded by minReplicas"))
+		}
+		if np.MaxSize != 0 {
+			errs = append(errs, field.Forbidden(path.Child("maxSize"), "autoscaled node pools are bounded by maxReplicas"))
+		}
+		return errs
+	}
+	if np.MinSize < 0 {
+		errs = append(errs, field.Invalid(path.Child("minSize"), np.MinSize, "must not be negative"))
+	}
+	if np.MaxSize < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxSize"), np.MaxSize, "must not be negative"))
+	}
+	if np.MaxSize > 0 && np.MinSize > np.MaxSize {
+		errs = append(errs, field.Invalid(path.Child("minSize"), np.MinSize, fmt.Sprintf("must not be greater than maxSize %d", np.MaxSize)))
+	}
+	return errs
+}
+
+func validatePlacement(path *field.Path, np NodePool, quickCreateVCN bool) field.ErrorList {
+	var errs field.ErrorList
+	for i, fd := range np.FaultDomains {
+		if !strings.HasPrefix(fd, faultDomainPrefix) {
+			errs = append(errs, field.Invalid(path.Child("faultDomains").Index(i), fd, fmt.Sprintf("must be a fault domain name, e.g. %s1", faultDomainPrefix)))
+		}
+	}
+	// quick create VCNs are managed by the cluster, so pools can't bring their own subnets or network security groups
+	if quickCreateVCN {
+		if np.Subnet != "" {
+			errs = append(errs, field.Forbidden(path.Child("subnet"), "node pool subnets require an existing VCN"))
+		}
+		if len(np.NSGs) > 0 {
+			errs = append(errs, field.Forbidden(path.Child("nsgs"), "node pool network security groups require an existing VCN"))
+		}
+		return errs
+	}
+	errs = append(errs, validateOCID(path.Child("subnet"), np.Subnet, false, "subnet")...)
+	for i, nsg := range np.NSGs {
+		errs = append(errs, validateOCID(path.Child("nsgs").Index(i), nsg, true, "networksecuritygroup")...)
+	}
+	return errs
+}
+
+func validateOCID(path *field.Path, ocid string, required bool, resourceTypes ...string) field.ErrorList {
+	if ocid == "" {
+		if required {
+			return field.ErrorList{field.Required(path, "")}
+		}
+		return nil
+	}
+	match := ocidPattern.FindStringSubmatch(ocid)
+	if match == nil {
+		return field.ErrorList{field.Invalid(path, ocid, "must be an OCID")}
+	}
+	for _, resourceType := range resourceTypes {
+		if match[1] == resourceType {
+			return nil
+		}
+	}
+	return field.ErrorList{field.Invalid(path, ocid, fmt.Sprintf("must be a %s OCID", strings.Join(resourceTypes, " or ")))}
+}
+
+func validateCIDR(path *field.Path, cidr string) field.ErrorList {
+	if cidr == "" {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return field.ErrorList{field.Invalid(path, cidr, "must be a CIDR block")}
+	}
+	return nil
+}
+
+// DescribeValidationErrors summarizes validation errors, one per line
+func DescribeValidationErrors(errs field.ErrorList) string {
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, "- "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package variables
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func validVariables() *Variables {
+	return &Variables{
+		Name:               "c-abcde",
+		DisplayName:        "my-cluster",
+		CompartmentID:      "ocid1.compartment.oc1..aaaaaaaaaaaa",
+		VCNID:              "ocid1.vcn.oc1.iad.aaaaaaaaaaaa",
+		WorkerNodeSubnet:   "ocid1.subnet.oc1.iad.aaaaaaaaaaaa",
+		ControlPlaneSubnet: "ocid1.subnet.oc1.iad.bbbbbbbbbbbb",
+		PodCIDR:            "10.244.0.0/16",
+		ClusterCIDR:        "10.96.0.0/16",
+		NodePools: []NodePool{
+			{Name: "np-1", Replicas: 1, Ocpus: 2, Shape: "VM.Standard.E4.Flex"},
+			{Name: "np-2", Replicas: 1, Shape: "VM.Standard2.1"},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var tests = []struct {
+		name   string
+		modify func(v *Variables)
+		fields []string
+	}{
+		{
+			"valid",
+			func(v *Variables) {},
+			nil,
+		},
+		{
+			"quick create VCN does not need subnets",
+			func(v *Variables) {
+				v.QuickCreateVCN = true
+				v.VCNID = ""
+				v.WorkerNodeSubnet = ""
+				v.ControlPlaneSubnet = ""
+			},
+			nil,
+		},
+		{
+			"invalid names",
+			func(v *Variables) {
+				v.Name = "C_1"
+				v.DisplayName = "My Cluster"
+			},
+			[]string{"name", "display-name"},
+		},
+		{
+			"missing compartment",
+			func(v *Variables) {
+				v.CompartmentID = ""
+			},
+			[]string{"compartment-id"},
+		},
+		{
+			"wrong OCID types",
+			func(v *Variables) {
+				v.VCNID = "ocid1.subnet.oc1.iad.aaaaaaaaaaaa"
+				v.ImageID = "not-an-ocid"
+			},
+			[]string{"image-id", "vcn-id"},
+		},
+		{
+			"malformed CIDRs",
+			func(v *Variables) {
+				v.PodCIDR = "10.244.0.0"
+				v.QuickCreateVCN = true
+				v.VCNCIDR = "10.0.0.0/8"
+			},
+			[]string{"pod-cidr", "vcn-cidr"},
+		},
+		{
+			"invalid node pools",
+			func(v *Variables) {
+				v.NodePools = []NodePool{
+					{Name: "np-1", Replicas: 1, Shape: "VM.Standard.E4.Flex"},
+					{Name: "np-1", Replicas: -1},
+				}
+			},
+			[]string{"node-pools[0].ocpus", "node-pools[1].name", "node-pools[1].replicas", "node-pools[1].shape"},
+		},
+		{
+			"invalid placement",
+			func(v *Variables) {
+				v.NodePools[0].FaultDomains = []string{"FD-1"}
+				v.NodePools[0].Subnet = "ocid1.vcn.oc1.iad.aaaaaaaaaaaa"
+				v.NodePools[0].NSGs = []string{"ocid1.networksecuritygroup.oc1.iad.aaaaaaaaaaaa"}
+			},
+			[]string{"node-pools[0].faultDomains[0]", "node-pools[0].subnet"},
+		},
+		{
+			"node pool subnets with a quick create VCN",
+			func(v *Variables) {
+				v.QuickCreateVCN = true
+				v.NodePools[0].Subnet = "ocid1.subnet.oc1.iad.aaaaaaaaaaaa"
+			},
+			[]string{"node-pools[0].subnet"},
+		},
+		{
+			"invalid labels and taints",
+			func(v *Variables) {
+				v.NodePools[0].Labels = map[string]string{"workload": "gpu", "bad key": "x", "tier": "not valid"}
+				v.NodePools[0].Taints = []Taint{
+					{Key: "nvidia.com/gpu", Effect: TaintEffectNoSchedule},
+					{Key: "batch", Effect: "Evict"},
+				}
+			},
+			[]string{"node-pools[0].labels", "node-pools[0].labels[tier]", "node-pools[0].taints[1].effect"},
+		},
+		{
+			"valid capacity types",
+			func(v *Variables) {
+				v.NodePools[0].CapacityType = CapacityTypePreemptible
+				v.NodePools[0].PreemptionAction = PreemptionActionTerminate
+				v.NodePools[1].CapacityType = CapacityTypeReserved
+				v.NodePools[1].CapacityReservationID = "ocid1.capacityreservation.oc1.iad.aaaaaaaaaaaa"
+				v.NodePools[1].AvailabilityDomains = []string{"Uocm:IAD-AD-1"}
+			},
+			nil,
+		},
+		{
+			"invalid capacity types",
+			func(v *Variables) {
+				v.NodePools[0].CapacityType = "spot"
+				v.NodePools[0].PreemptionAction = PreemptionActionTerminate
+				v.NodePools[1].CapacityType = CapacityTypeReserved
+			},
+			[]string{"node-pools[0].capacityType", "node-pools[0].preemptionAction", "node-pools[1].capacityReservationId", "node-pools[1].availabilityDomains"},
+		},
+		{
+			"reserved capacity with a preemption action",
+			func(v *Variables) {
+				v.NodePools[1].CapacityType = CapacityTypeReserved
+				v.NodePools[1].CapacityReservationID = "ocid1.capacityreservation.oc1.iad.aaaaaaaaaaaa"
+				v.NodePools[1].AvailabilityDomains = []string{"Uocm:IAD-AD-1"}
+				v.NodePools[1].PreemptionAction = PreemptionActionTerminate
+			},
+			[]string{"node-pools[1].preemptionAction"},
+		},
+		{
+			"node pool images",
+			func(v *Variables) {
+				v.NodePools[0].Image = "Oracle-Linux-8.7-aarch64-2023.05.24-0-OKE-1.26.2-625"
+				v.NodePools[1].Image = "ocid1.subnet.oc1.iad.aaaaaaaaaaaa"
+			},
+			[]string{"node-pools[1].image"},
+		},
+		{
+			"invalid node cycling",
+			func(v *Variables) {
+				v.NodeCyclingMaxSurge = "fast"
+				v.NodeEvictionGraceDuration = "PT2H"
+				v.NodePools[0].MaxUnavailable = "150%"
+				v.NodePools[1].MaxSurge = "0%"
+			},
+			[]string{"node-cycling-max-surge", "node-eviction-grace-duration", "node-pools[0].maxUnavailable", "node-pools[1].maxUnavailable"},
+		},
+		{
+			"node pool bounds",
+			func(v *Variables) {
+				v.NodePools[0].MinReplicas = 1
+				v.NodePools[0].MaxReplicas = 3
+				v.NodePools[0].MinSize = 1
+				v.NodePools[0].MaxSize = 5
+				v.NodePools[1].MinSize = 4
+				v.NodePools[1].MaxSize = 2
+			},
+			[]string{"node-pools[0].minSize", "node-pools[0].maxSize", "node-pools[1].minSize"},
+		},
+		{
+			"invalid autoscaling",
+			func(v *Variables) {
+				v.NodePools[0].MinReplicas = 6
+				v.NodePools[0].MaxReplicas = 5
+				v.NodePools[1].MinReplicas = 1
+			},
+			[]string{"node-pools[0].minReplicas", "node-pools[1].maxReplicas"},
+		},
+		{
+			"options that are not JSON",
+			func(v *Variables) {
+				v.RawNodePools = []string{`{"name":"np-1","replicas":1,"ocpus":2,"shape":"VM.Standard.E4.Flex"}`, "np-2"}
+				v.RawAddons = []string{"CertManager"}
+				v.RawFreeformTags = "team=platform"
+				v.parse()
+			},
+			[]string{"node-pools[1]", "addons[0]", "freeform-tags"},
+		},
+		{
+			"invalid node pool rollout timeout",
+			func(v *Variables) {
+				v.NodePoolRolloutTimeout = "45"
+			},
+			[]string{"node-pool-rollout-timeout"},
+		},
+		{
+			"objectstorage snapshots without a bucket",
+			func(v *Variables) {
+				v.SnapshotBackend = SnapshotBackendObjectStorage
+			},
+			[]string{"snapshot-bucket"},
+		},
+		{
+			"unknown snapshot backend",
+			func(v *Variables) {
+				v.SnapshotBackend = "s3"
+			},
+			[]string{"snapshot-backend"},
+		},
+		{
+			"invalid KMS keys",
+			func(v *Variables) {
+				v.KMSKeyID = "ocid1.vault.oc1.iad.aaaaaaaaaaaa"
+				v.NodePools[0].BootVolumeKMSKeyID = "ocid1.key.oc1.iad.aaaaaaaaaaaa"
+				v.NodePools[1].BootVolumeKMSKeyID = "key"
+			},
+			[]string{"kms-key-id", "node-pools[1].bootVolumeKmsKeyId"},
+		},
+		{
+			"image verification without keys",
+			func(v *Variables) {
+				v.ImageVerification = true
+			},
+			[]string{"image-verification-kms-key-ids"},
+		},
+		{
+			"invalid image verification keys",
+			func(v *Variables) {
+				v.ImageVerification = true
+				v.ImageVerificationKMSKeyIDs = []string{"ocid1.key.oc1.iad.aaaaaaaaaaaa", "", "ocid1.vault.oc1.iad.aaaaaaaaaaaa"}
+			},
+			[]string{"image-verification-kms-key-ids[1]", "image-verification-kms-key-ids[2]"},
+		},
+		{
+			"invalid add-ons",
+			func(v *Variables) {
+				v.NodePools[0].MinReplicas = 1
+				v.NodePools[0].MaxReplicas = 3
+				v.Addons = []Addon{
+					{Name: "CertManager", Configurations: map[string]string{"": "1"}},
+					{Name: "CertManager"},
+					{Version: "v1.0.0"},
+					{Name: "ClusterAutoscaler"},
+				}
+			},
+			[]string{"addons[0].configurations", "addons[1].name", "addons[2].name", "addons[3].name"},
+		},
+		{
+			"invalid tags",
+			func(v *Variables) {
+				v.FreeformTags = map[string]string{"team": "platform", "cost center": "42", RancherClusterIDTag: "c-other"}
+				v.DefinedTags = map[string]map[string]string{"": {"CostCenter": "42"}}
+				v.NodePools[1].FreeformTags = map[string]string{"": "ml"}
+				v.NodePools[1].DefinedTags = map[string]map[string]string{"Operations": {"cost.center": "42"}}
+			},
+			[]string{"node-pools[1].freeformTags", "node-pools[1].definedTags[Operations][cost.center]", "freeform-tags[cost center]", "freeform-tags[rancher-cluster-id]", "defined-tags"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validVariables()
+			tt.modify(v)
+			errs := v.Validate()
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestDescribeValidationErrors(t *testing.T) {
+	v := validVariables()
+	v.CompartmentID = ""
+	v.PodCIDR = "x"
+	assert.Equal(t, "- compartment-id: Required value\n- pod-cidr: Invalid value: \"x\": must be a CIDR block", DescribeValidationErrors(v.Validate()))
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/common/auth"
 	"github.com/rancher/kontainer-engine/drivers/options"
 	"github.com/rancher/kontainer-engine/store"
 	"github.com/rancher/kontainer-engine/types"
@@ -17,13 +18,16 @@ import (
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/gvr"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/k8s"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci"
+	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/region"
 	"github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/version"
 	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"strings"
+	"time"
 )
 
 const (
@@ -32,12 +36,26 @@ const (
 	DefaultVolumeGbs               = 100
 	DefaultNodePVTransitEncryption = true
 	DefaultVMShape                 = "VM.Standard.E4.Flex"
+	DefaultSnapshotDirectory       = "/var/lib/kontainer-engine-driver-oke-capi/snapshots"
+	DefaultClusterAutoscalerImage  = "registry.k8s.io/autoscaling/cluster-autoscaler:v1.26.2"
+	DefaultNodePoolRolloutTimeout  = 30 * time.Minute
 	ProviderId                     = `oci://{{ ds["id"] }}`
+
+	SnapshotBackendObjectStorage = "objectstorage"
+	SnapshotBackendFilesystem    = "filesystem"
+)
+
+const (
+	AuthTypeUserPrincipal     = "UserPrincipal"
+	AuthTypeInstancePrincipal = "InstancePrincipal"
+	AuthTypeWorkloadIdentity  = "WorkloadIdentity"
 )
 
 const (
 	kubeconfigName = "%s-kubeconfig"
 
+	cloudCredentialAuthTypeField = "ocicredentialConfig-authType"
+
 	loadBalancerSubnetRole         = "service-lb"
 	controlPlaneEndpointSubnetRole = "control-plane-endpoint"
 	workerSubnetRole               = "worker"
@@ -88,6 +106,13 @@ type Subnet struct {
 	Type string
 }
 
+// NetworkSecurityGroup is an existing network security group used by the cluster
+type NetworkSecurityGroup struct {
+	Id   string
+	Role string
+	Name string
+}
+
 type NodePool struct {
 	Name       string `json:"name"`
 	Replicas   int64  `json:"replicas"`
@@ -96,10 +121,60 @@ type NodePool struct {
 	VolumeSize int64  `json:"volumeSize"`
 	Shape      string `json:"shape"`
 	Version    string `json:"version"`
+	// MinSize and MaxSize bound the replicas of pools that aren't autoscaled when the cluster is resized. A MaxSize of 0 is unbounded.
+	MinSize int64 `json:"minSize,omitempty"`
+	MaxSize int64 `json:"maxSize,omitempty"`
+	// MinReplicas and MaxReplicas are the cluster autoscaler's bounds. Setting MaxReplicas enables autoscaling for the pool.
+	MinReplicas int64 `json:"minReplicas,omitempty"`
+	MaxReplicas int64 `json:"maxReplicas,omitempty"`
+	// AvailabilityDomains the pool is placed in, by name or by suffix, e.g. AD-1.
+	// Pools without availability domains are spread across every availability domain in the region.
+	AvailabilityDomains []string `json:"availabilityDomains,omitempty"`
+	// FaultDomains the pool is placed in within each availability domain, e.g. FAULT-DOMAIN-1
+	FaultDomains []string `json:"faultDomains,omitempty"`
+	// Subnet is the OCID of the pool's worker subnet. Pools without a subnet use the cluster's worker subnet.
+	Subnet string `json:"subnet,omitempty"`
+	// NSGs are the OCIDs of network security groups for the pool's worker nodes
+	NSGs []string `json:"nsgs,omitempty"`
+	// Labels and Taints are set on the pool's nodes when they register. Changes replace the nodes through node cycling.
+	Labels map[string]string `json:"labels,omitempty"`
+	Taints []Taint           `json:"taints,omitempty"`
+	// CapacityType is on-demand (the default), preemptible or reserved
+	CapacityType string `json:"capacityType,omitempty"`
+	// PreemptionAction is what happens to preemptible nodes when they are reclaimed, TERMINATE or TERMINATE_PRESERVE_BOOT_VOLUME
+	PreemptionAction string `json:"preemptionAction,omitempty"`
+	// CapacityReservationID is the OCID of the capacity reservation reserved pools launch nodes in
+	CapacityReservationID string `json:"capacityReservationId,omitempty"`
+	// Image is the display name or OCID of the pool's node image. Pools without an image use the cluster's image.
+	Image string `json:"image,omitempty"`
+	// ActualImage is the OCID of the pool's resolved image
+	ActualImage string `json:"actualImage,omitempty"`
+	// MaxSurge and MaxUnavailable are how many nodes, e.g. 2, or what percentage of the pool's nodes, e.g. 25%,
+	// are added or unavailable while the pool's nodes are cycled
+	MaxSurge       string `json:"maxSurge,omitempty"`
+	MaxUnavailable string `json:"maxUnavailable,omitempty"`
+	// EvictionGraceDuration is how long nodes are drained before they are deleted, as an ISO 8601 duration up to PT60M
+	EvictionGraceDuration string `json:"evictionGraceDuration,omitempty"`
+	// ForceDeleteAfterGraceDuration deletes nodes that aren't drained after the eviction grace duration
+	ForceDeleteAfterGraceDuration *bool `json:"forceDeleteAfterGraceDuration,omitempty"`
+	// BootVolumeKMSKeyID is the OCID of the KMS key that encrypts the boot volumes of the pool's nodes
+	BootVolumeKMSKeyID string `json:"bootVolumeKmsKeyId,omitempty"`
+	// FreeformTags and DefinedTags are added to the cluster's tags on the pool and its nodes, replacing cluster tags with the same key
+	FreeformTags map[string]string            `json:"freeformTags,omitempty"`
+	DefinedTags  map[string]map[string]string `json:"definedTags,omitempty"`
+}
+
+// Autoscaled is true if the pool's replicas are managed by the cluster autoscaler
+func (np NodePool) Autoscaled() bool {
+	return np.MaxReplicas > 0
 }
 
 var OCIClientGetter = func(v *Variables) (oci.Client, error) {
-	return oci.NewClient(v.GetConfigurationProvider())
+	provider, err := v.GetConfigurationProvider()
+	if err != nil {
+		return nil, err
+	}
+	return oci.NewClient(provider)
 }
 
 type (
@@ -110,15 +185,27 @@ type (
 		Namespace   string
 
 		QuickCreateVCN     bool
+		VCNCIDR            string
 		VCNID              string
 		WorkerNodeSubnet   string
 		ControlPlaneSubnet string
 		LoadBalancerSubnet string
 		PodSubnet          string
 		// Parsed subnets
-		Subnets     []Subnet `json:"subnets,omitempty"`
+		Subnets []Subnet `json:"subnets,omitempty"`
+		// NetworkSecurityGroups are the existing network security groups of node pools
+		NetworkSecurityGroups []NetworkSecurityGroup `json:"networkSecurityGroups,omitempty"`
+		// AvailabilityDomains are the names of the availability domains in the region
+		AvailabilityDomains []string `json:"availabilityDomains,omitempty"`
+		// Network is the planned addressing of a quick create VCN
+		Network     *NetworkPlan `json:"network,omitempty"`
 		PodCIDR     string
 		ClusterCIDR string
+		// PrivateEndpoint creates the Kubernetes API endpoint without a public IP
+		PrivateEndpoint bool
+		// APIServerEndpoint and APIServerProxy are how the driver reaches a private Kubernetes API endpoint
+		APIServerEndpoint string
+		APIServerProxy    string
 
 		// Cluster topology and configuration
 		KubernetesVersion string
@@ -128,12 +215,42 @@ type (
 		ApplyYAMLS        []string
 		// Parsed node pools
 		NodePools []NodePool
+		// NodePoolDistribution is how nodes are distributed across node pools when the cluster is resized
+		NodePoolDistribution string
+		// ClusterAutoscalerImage is deployed for clusters with autoscaled node pools
+		ClusterAutoscalerImage string
+		// RawAddons are the cluster's OKE add-ons
+		RawAddons []string
+		// Parsed add-ons
+		Addons []Addon
+		// Node cycling defaults for node pools that don't set their own
+		NodeCyclingMaxSurge       string
+		NodeCyclingMaxUnavailable string
+		NodeEvictionGraceDuration string
+		NodeEvictionForceDelete   bool
 
 		// ImageID is looked up by display name
 		ImageDisplayName string
 		ImageID          string
 		ActualImage      string
 
+		// KMSKeyID is the OCID of the KMS key that encrypts the cluster's Kubernetes secrets
+		KMSKeyID string
+		// NodePVTransitEncryption encrypts the traffic between nodes and their boot and block volumes
+		NodePVTransitEncryption bool
+		// ImageVerification only runs images signed by one of the ImageVerificationKMSKeyIDs
+		ImageVerification          bool
+		ImageVerificationKMSKeyIDs []string
+
+		// RawFreeformTags and RawDefinedTags are JSON objects of the tags on every OCI resource of the cluster
+		RawFreeformTags string
+		RawDefinedTags  string
+		// Parsed tags
+		FreeformTags map[string]string
+		DefinedTags  map[string]map[string]string
+		// NodePoolTags are the tags the driver set on each OKE node pool, so tags removed from a pool are removed from OKE
+		NodePoolTags map[string]Tags `json:"nodePoolTags,omitempty"`
+
 		// Private registry
 		PrivateRegistry string
 
@@ -146,6 +263,8 @@ type (
 		DockerConfigJson        string
 
 		// OCI Credentials
+		// AuthType is UserPrincipal, InstancePrincipal or WorkloadIdentity. Empty values are treated as UserPrincipal.
+		AuthType             string
 		CloudCredentialId    string
 		CompartmentID        string
 		Fingerprint          string
@@ -154,6 +273,8 @@ type (
 		Region               string
 		Tenancy              string
 		User                 string
+		// RegionInfo is the metadata of Region
+		RegionInfo *region.Region `json:"regionInfo,omitempty"`
 
 		// Verrazzano settings
 		InstallVerrazzano bool
@@ -163,8 +284,28 @@ type (
 		VerrazzanoVersion   string
 		VerrazzanoTag       string
 
+		// Managed cluster snapshot settings
+		SnapshotBackend   string
+		SnapshotBucket    string
+		SnapshotNamespace string
+		SnapshotDirectory string
+		// SnapshotSecrets captures Secrets in snapshots, which are stored unencrypted
+		SnapshotSecrets bool
+
+		// PlanUpdate plans updates without applying them
+		PlanUpdate bool
+		// PauseNodePoolRollout leaves node pools that change version at their current version
+		PauseNodePoolRollout bool
+		// NodePoolRolloutTimeout is how long a rollout waits for each node pool to be upgraded, e.g. 45m
+		NodePoolRolloutTimeout string
+
 		// Supplied for templating
 		ProviderId string
+
+		// currentKubernetesVersion is the cluster's Kubernetes version before an upgrade
+		currentKubernetesVersion string
+		// parseErrors are the node pools, add-ons and tags that could not be deserialized, reported by Validate
+		parseErrors field.ErrorList
 	}
 )
 
@@ -180,9 +321,11 @@ func NewFromOptions(ctx context.Context, driverOptions *types.DriverOptions) (*V
 		CloudCredentialId: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.CloudCredentialId, "cloudCredentialId").(string),
 		Region:            options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.Region, "region").(string),
 		CompartmentID:     options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.CompartmentID, "compartmentId").(string),
+		AuthType:          options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.AuthType, "authType").(string),
 
 		// Networking
 		QuickCreateVCN:     options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.QuickCreateVCN, "quickCreateVcn").(bool),
+		VCNCIDR:            options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.VCNCIDR, "vcnCidr").(string),
 		CNIType:            options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.CNIType, "cniType").(string),
 		VCNID:              options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.VcnID, "vcnId").(string),
 		WorkerNodeSubnet:   options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.WorkerNodeSubnet, "workerNodeSubnet").(string),
@@ -191,12 +334,37 @@ func NewFromOptions(ctx context.Context, driverOptions *types.DriverOptions) (*V
 		PodSubnet:          options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.PodSubnet, "podSubnet").(string),
 		PodCIDR:            options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.PodCIDR, "podCidr").(string),
 		ClusterCIDR:        options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.ClusterCIDR, "clusterCidr").(string),
+		PrivateEndpoint:    options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.PrivateEndpoint, "privateEndpoint").(bool),
+		APIServerEndpoint:  options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.APIServerEndpoint, "apiServerEndpoint").(string),
+		APIServerProxy:     options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.APIServerProxy, "apiServerProxy").(string),
 
 		// VM settings
 		ImageDisplayName: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.ImageDisplayName, "imageDisplayName").(string),
 		RawNodePools:     options.GetValueFromDriverOptions(driverOptions, types.StringSliceType, driverconst.RawNodePools, "nodePools").(*types.StringSlice).Value,
 		ApplyYAMLS:       options.GetValueFromDriverOptions(driverOptions, types.StringSliceType, driverconst.ApplyYAMLs, "applyYamls").(*types.StringSlice).Value,
 
+		// Encryption
+		KMSKeyID:                options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.KMSKeyID, "kmsKeyId").(string),
+		NodePVTransitEncryption: options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.NodePVTransitEncryption, "nodePvTransitEncryption").(bool),
+
+		// Image verification
+		ImageVerification:          options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.ImageVerification, "imageVerification").(bool),
+		ImageVerificationKMSKeyIDs: options.GetValueFromDriverOptions(driverOptions, types.StringSliceType, driverconst.ImageVerificationKMSKeyIDs, "imageVerificationKmsKeyIds").(*types.StringSlice).Value,
+
+		NodePoolDistribution:   options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.NodePoolDistribution, "nodePoolDistribution").(string),
+		ClusterAutoscalerImage: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.ClusterAutoscalerImage, "clusterAutoscalerImage").(string),
+		RawAddons:              options.GetValueFromDriverOptions(driverOptions, types.StringSliceType, driverconst.RawAddons, "addons").(*types.StringSlice).Value,
+
+		// Node cycling
+		NodeCyclingMaxSurge:       options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.NodeCyclingMaxSurge, "nodeCyclingMaxSurge").(string),
+		NodeCyclingMaxUnavailable: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.NodeCyclingMaxUnavailable, "nodeCyclingMaxUnavailable").(string),
+		NodeEvictionGraceDuration: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.NodeEvictionGraceDuration, "nodeEvictionGraceDuration").(string),
+		NodeEvictionForceDelete:   options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.NodeEvictionForceDelete, "nodeEvictionForceDelete").(bool),
+
+		// Tags
+		RawFreeformTags: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.RawFreeformTags, "freeformTags").(string),
+		RawDefinedTags:  options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.RawDefinedTags, "definedTags").(string),
+
 		// Private Registry
 		PrivateRegistry: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.PrivateRegistry, "privateRegistry").(string),
 
@@ -211,6 +379,17 @@ func NewFromOptions(ctx context.Context, driverOptions *types.DriverOptions) (*V
 		VerrazzanoVersion:  options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.VerrazzanoVersion, "verrazzanoVersion").(string),
 		InstallVerrazzano:  options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.InstallVerrazzano, "installVerrazzano").(bool),
 
+		// Snapshot settings
+		SnapshotBackend:   options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.SnapshotBackend, "snapshotBackend").(string),
+		SnapshotBucket:    options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.SnapshotBucket, "snapshotBucket").(string),
+		SnapshotNamespace: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.SnapshotNamespace, "snapshotNamespace").(string),
+		SnapshotDirectory: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.SnapshotDirectory, "snapshotDirectory").(string),
+		SnapshotSecrets:   options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.SnapshotSecrets, "snapshotSecrets").(bool),
+
+		PlanUpdate:             options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.PlanUpdate, "planUpdate").(bool),
+		PauseNodePoolRollout:   options.GetValueFromDriverOptions(driverOptions, types.BoolType, driverconst.PauseNodePoolRollout, "pauseNodePoolRollout").(bool),
+		NodePoolRolloutTimeout: options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.NodePoolRolloutTimeout, "nodePoolRolloutTimeout").(string),
+
 		ImageID:    options.GetValueFromDriverOptions(driverOptions, types.StringType, driverconst.ImageId, "imageId").(string),
 		ProviderId: ProviderId,
 	}
@@ -233,12 +412,28 @@ func (v *Variables) SetUpdateValues(ctx context.Context, vNew *Variables) error
 	if v.CreateImagePullSecrets && !vNew.CreateImagePullSecrets {
 		v.DeleteImagePullSecrets = true
 	}
-	v.KubernetesVersion = vNew.KubernetesVersion
+	v.currentKubernetesVersion = v.KubernetesVersion
+	// An empty version keeps the cluster's version, rather than upgrading it to the latest version
+	if vNew.KubernetesVersion != "" {
+		v.KubernetesVersion = vNew.KubernetesVersion
+	}
 	v.ImageDisplayName = vNew.ImageDisplayName
 	v.RawNodePools = vNew.RawNodePools
+	v.NodePoolDistribution = vNew.NodePoolDistribution
+	v.ClusterAutoscalerImage = vNew.ClusterAutoscalerImage
+	v.RawAddons = vNew.RawAddons
+	v.RawFreeformTags = vNew.RawFreeformTags
+	v.RawDefinedTags = vNew.RawDefinedTags
+	v.NodeCyclingMaxSurge = vNew.NodeCyclingMaxSurge
+	v.NodeCyclingMaxUnavailable = vNew.NodeCyclingMaxUnavailable
+	v.NodeEvictionGraceDuration = vNew.NodeEvictionGraceDuration
+	v.NodeEvictionForceDelete = vNew.NodeEvictionForceDelete
 	v.SSHPublicKey = vNew.SSHPublicKey
 	v.DisplayName = vNew.DisplayName
 	v.ImageID = vNew.ImageID
+	v.NodePVTransitEncryption = vNew.NodePVTransitEncryption
+	v.ImageVerification = vNew.ImageVerification
+	v.ImageVerificationKMSKeyIDs = vNew.ImageVerificationKMSKeyIDs
 	v.ApplyYAMLS = vNew.ApplyYAMLS
 	v.InstallVerrazzano = vNew.InstallVerrazzano
 	v.VerrazzanoVersion = vNew.VerrazzanoVersion
@@ -248,17 +443,34 @@ func (v *Variables) SetUpdateValues(ctx context.Context, vNew *Variables) error
 	v.ImagePullSecretPassword = vNew.ImagePullSecretPassword
 	v.ImagePullSecretEmail = vNew.ImagePullSecretEmail
 	v.PrivateRegistry = vNew.PrivateRegistry
+	v.AuthType = vNew.AuthType
+	v.SnapshotBackend = vNew.SnapshotBackend
+	v.SnapshotBucket = vNew.SnapshotBucket
+	v.SnapshotNamespace = vNew.SnapshotNamespace
+	v.SnapshotDirectory = vNew.SnapshotDirectory
+	v.SnapshotSecrets = vNew.SnapshotSecrets
+	v.PlanUpdate = vNew.PlanUpdate
+	v.PauseNodePoolRollout = vNew.PauseNodePoolRollout
+	v.NodePoolRolloutTimeout = vNew.NodePoolRolloutTimeout
+	v.APIServerEndpoint = vNew.APIServerEndpoint
+	v.APIServerProxy = vNew.APIServerProxy
 	return v.SetDynamicValues(ctx)
 }
 
 // SetDynamicValues sets dynamic values
 func (v *Variables) SetDynamicValues(ctx context.Context) error {
-	// deserialize node pools
-	nodePools, err := v.ParseNodePools()
-	if err != nil {
+	// deserialize node pools, add-ons and tags
+	v.parse()
+	if len(v.parseErrors) > 0 {
+		// the options are invalid, and Validate reports every problem
+		return nil
+	}
+	if v.ClusterAutoscalerImage == "" {
+		v.ClusterAutoscalerImage = DefaultClusterAutoscalerImage
+	}
+	if err := v.setNetworkPlan(); err != nil {
 		return err
 	}
-	v.NodePools = nodePools
 
 	// setup OCI client for dynamic values
 	ki, err := k8s.InjectedInterface()
@@ -273,14 +485,43 @@ func (v *Variables) SetDynamicValues(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	// look up the region's metadata, including regions newer than the driver
+	if err := v.setRegionInfo(ctx, ociClient); err != nil {
+		return err
+	}
+	// default and validate the Kubernetes version using the versions supported by OKE
+	if err := v.setKubernetesVersion(ctx, ociClient); err != nil {
+		return err
+	}
 	// get image OCID from OCI
 	if err := v.setImageId(ctx, ociClient); err != nil {
 		return err
 	}
+	// size node pools within their shapes' limits
+	shapes, err := v.getShapes(ctx, ociClient)
+	if err != nil {
+		return err
+	}
+	if err := v.validateShapes(shapes); err != nil {
+		return err
+	}
+	// get each node pool's image OCID, matching the architecture of its shape
+	if err := v.setNodePoolImages(ctx, ociClient, shapes); err != nil {
+		return err
+	}
+	// check the KMS keys are usable in the cluster's compartment
+	if err := v.validateKMSKeys(ctx, ociClient); err != nil {
+		return err
+	}
+	// place node pools in the region's availability domains
+	if err := v.setAvailabilityDomains(ctx, ociClient); err != nil {
+		return err
+	}
 	// get subnet metadata from OCI
 	if err := v.setSubnets(ctx, ociClient); err != nil {
 		return err
 	}
+	v.setNetworkSecurityGroups()
 	if v.CreateImagePullSecrets {
 		if err := v.SetDockerConfigJson(); err != nil {
 			return err
@@ -295,6 +536,82 @@ func (v *Variables) SetDynamicValues(ctx context.Context) error {
 	return nil
 }
 
+// setRegionInfo looks up the region's metadata. Regions missing from the known regions are looked up in OCI, regions OCI
+// doesn't have are rejected, and quick create VCNs need a region for their Oracle Services Network security rules.
+func (v *Variables) setRegionInfo(ctx context.Context, client oci.Client) error {
+	if v.Region == "" {
+		if v.QuickCreateVCN {
+			return errors.New("a region is required to quick create a VCN")
+		}
+		v.RegionInfo = nil
+		return nil
+	}
+	if r, err := region.Lookup(v.Region); err == nil {
+		v.RegionInfo = r
+		return nil
+	}
+	regions, err := client.GetRegions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get OCI regions: %v", err)
+	}
+	name := strings.ToLower(strings.TrimSpace(v.Region))
+	for _, r := range regions {
+		if r.Name == nil || r.Key == nil {
+			continue
+		}
+		if strings.ToLower(*r.Name) == name || strings.ToLower(*r.Key) == name {
+			v.RegionInfo = region.New(*r.Name, *r.Key)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown OCI region %s", v.Region)
+}
+
+// SetKubernetesVersion upgrades the cluster's Kubernetes version, validating the upgrade
+func (v *Variables) SetKubernetesVersion(ctx context.Context, kubernetesVersion string) error {
+	v.currentKubernetesVersion = v.KubernetesVersion
+	if kubernetesVersion != "" {
+		v.KubernetesVersion = kubernetesVersion
+	}
+	return v.SetDynamicValues(ctx)
+}
+
+// setKubernetesVersion defaults the Kubernetes version of new clusters to the latest supported version,
+// and keeps the version of existing clusters. New versions must be supported by OKE, and be a valid upgrade from the cluster's current version.
+func (v *Variables) setKubernetesVersion(ctx context.Context, client oci.Client) error {
+	if v.KubernetesVersion == "" {
+		v.KubernetesVersion = v.currentKubernetesVersion
+	}
+	if v.KubernetesVersion == "" || v.KubernetesVersion != v.currentKubernetesVersion {
+		supported, err := client.GetKubernetesVersions(ctx, v.CompartmentID)
+		if err != nil {
+			return fmt.Errorf("failed to get supported Kubernetes versions: %v", err)
+		}
+		if v.KubernetesVersion == "" {
+			if v.KubernetesVersion, err = version.LatestKubernetesVersion(supported); err != nil {
+				return err
+			}
+		}
+		if err := version.ValidateKubernetesVersion(v.KubernetesVersion, supported); err != nil {
+			return err
+		}
+		if v.currentKubernetesVersion != "" {
+			if err := version.ValidateKubernetesUpgrade(v.currentKubernetesVersion, v.KubernetesVersion); err != nil {
+				return err
+			}
+		}
+	}
+	for _, np := range v.NodePools {
+		if np.Version == "" {
+			continue
+		}
+		if err := version.ValidateNodePoolVersion(v.KubernetesVersion, np.Version); err != nil {
+			return fmt.Errorf("node pool %s: %v", np.Name, err)
+		}
+	}
+	return nil
+}
+
 // SetDockerConfigJson sets the docker configuration payload for the image pull secret
 func (v *Variables) SetDockerConfigJson() error {
 	if v.PrivateRegistry == "" || v.ImagePullSecretUsername == "" || v.ImagePullSecretPassword == "" || v.ImagePullSecretEmail == "" {
@@ -310,14 +627,28 @@ func (v *Variables) SetDockerConfigJson() error {
 	return nil
 }
 
-// GetConfigurationProvider creates a new configuration provider from Variables
-func (v *Variables) GetConfigurationProvider() common.ConfigurationProvider {
+// GetConfigurationProvider creates a new configuration provider from Variables, using the cluster's authentication type
+func (v *Variables) GetConfigurationProvider() (common.ConfigurationProvider, error) {
+	switch v.AuthType {
+	case AuthTypeInstancePrincipal:
+		if v.Region != "" {
+			return auth.InstancePrincipalConfigurationProviderForRegion(common.StringToRegion(v.Region))
+		}
+		return auth.InstancePrincipalConfigurationProvider()
+	case AuthTypeWorkloadIdentity:
+		return auth.OkeWorkloadIdentityConfigurationProvider()
+	}
 	var passphrase *string
 	if len(v.PrivateKeyPassphrase) > 0 {
 		passphrase = &v.PrivateKeyPassphrase
 	}
 	privateKey := strings.TrimSpace(v.PrivateKey)
-	return common.NewRawConfigurationProvider(v.Tenancy, v.User, v.Region, v.Fingerprint, privateKey, passphrase)
+	return common.NewRawConfigurationProvider(v.Tenancy, v.User, v.Region, v.Fingerprint, privateKey, passphrase), nil
+}
+
+// UsesUserPrincipal is true if the cluster authenticates to OCI with a user's API key
+func (v *Variables) UsesUserPrincipal() bool {
+	return v.AuthType == "" || v.AuthType == AuthTypeUserPrincipal
 }
 
 // GetCAPIClusterKubeConfig fetches the cluster's kubeconfig
@@ -371,17 +702,58 @@ func (v *Variables) Version() *types.KubernetesVersion {
 }
 
 func (v *Variables) ParseNodePools() ([]NodePool, error) {
+	nodePools, errs := v.parseNodePools(field.NewPath(driverconst.RawNodePools))
+	if len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return nodePools, nil
+}
+
+// parseNodePools deserializes the node pools that are valid JSON, and describes the others
+func (v *Variables) parseNodePools(path *field.Path) ([]NodePool, field.ErrorList) {
 	var nodePools []NodePool
+	var errs field.ErrorList
 
-	for _, rawNodePool := range v.RawNodePools {
+	for i, rawNodePool := range v.RawNodePools {
 		nodePool := NodePool{}
 		if err := json.Unmarshal([]byte(rawNodePool), &nodePool); err != nil {
-			return nil, err
+			errs = append(errs, field.Invalid(path.Index(i), rawNodePool, err.Error()))
+			continue
 		}
+		applyNodePoolDefaults(&nodePool)
 		nodePools = append(nodePools, nodePool)
 	}
 
-	return nodePools, nil
+	return nodePools, errs
+}
+
+// parse deserializes the node pools, add-ons and tags, keeping any problems for Validate
+func (v *Variables) parse() {
+	var errs, parseErrs field.ErrorList
+	v.NodePools, parseErrs = v.parseNodePools(field.NewPath(driverconst.RawNodePools))
+	errs = append(errs, parseErrs...)
+	v.Addons, parseErrs = v.parseAddons(field.NewPath(driverconst.RawAddons))
+	errs = append(errs, parseErrs...)
+	errs = append(errs, v.parseTags(field.NewPath(driverconst.RawFreeformTags), field.NewPath(driverconst.RawDefinedTags))...)
+	v.parseErrors = errs
+}
+
+// RolloutTimeout is how long a rollout waits for each node pool to be upgraded
+func (v *Variables) RolloutTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(v.NodePoolRolloutTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return DefaultNodePoolRolloutTimeout
+}
+
+// IsAutoscaled is true if any node pool is managed by the cluster autoscaler
+func (v *Variables) IsAutoscaled() bool {
+	for _, np := range v.NodePools {
+		if np.Autoscaled() {
+			return true
+		}
+	}
+	return false
 }
 
 func (v *Variables) setImageId(ctx context.Context, client oci.Client) error {
@@ -427,10 +799,31 @@ func (v *Variables) setSubnets(ctx context.Context, client oci.Client) error {
 	if err := addSubnetForRole(v.PodSubnet, podSubnetRole); err != nil {
 		return err
 	}
+	// node pools may use their own worker subnets
+	for _, np := range v.NodePools {
+		if np.Subnet == "" || hasSubnet(subnets, np.Subnet) {
+			continue
+		}
+		subnet, err := getSubnetById(ctx, client, np.Subnet, workerSubnetRole)
+		if err != nil {
+			return err
+		}
+		subnet.Name = fmt.Sprintf("%s-%s", workerSubnetRole, np.Name)
+		subnets = append(subnets, *subnet)
+	}
 	v.Subnets = subnets
 	return nil
 }
 
+func hasSubnet(subnets []Subnet, subnetId string) bool {
+	for _, subnet := range subnets {
+		if subnet.Id == subnetId {
+			return true
+		}
+	}
+	return false
+}
+
 func getSubnetById(ctx context.Context, client oci.Client, subnetId, role string) (*Subnet, error) {
 	sn, err := client.GetSubnetById(ctx, subnetId)
 	if err != nil {
@@ -446,8 +839,13 @@ func getSubnetById(ctx context.Context, client oci.Client, subnetId, role string
 	}, nil
 }
 
-// SetupOCIAuth dynamically loads OCI authentication
+// SetupOCIAuth dynamically loads OCI authentication.
+// The authentication type is taken from the driver options, or from the cloud credential if unset.
+// Instance principal and workload identity authentication do not require a cloud credential.
 func SetupOCIAuth(ctx context.Context, client kubernetes.Interface, v *Variables) error {
+	if v.CloudCredentialId == "" && !v.UsesUserPrincipal() {
+		return validateAuthType(v.AuthType)
+	}
 	ccName, ccNamespace := v.cloudCredentialNameAndNamespace()
 	cc, err := client.CoreV1().Secrets(ccNamespace).Get(ctx, ccName, metav1.GetOptions{})
 	// Failed to retrieve cloud credentials
@@ -455,14 +853,36 @@ func SetupOCIAuth(ctx context.Context, client kubernetes.Interface, v *Variables
 		return err
 	}
 
+	if authType := string(cc.Data[cloudCredentialAuthTypeField]); v.AuthType == "" && authType != "" {
+		v.AuthType = authType
+	}
+	if err := validateAuthType(v.AuthType); err != nil {
+		return err
+	}
+	v.Tenancy = string(cc.Data["ocicredentialConfig-tenancyId"])
+	if !v.UsesUserPrincipal() {
+		// don't keep API keys for principals that don't use them
+		v.User = ""
+		v.Fingerprint = ""
+		v.PrivateKeyPassphrase = ""
+		v.PrivateKey = ""
+		return nil
+	}
 	v.User = string(cc.Data["ocicredentialConfig-userId"])
 	v.Fingerprint = string(cc.Data["ocicredentialConfig-fingerprint"])
-	v.Tenancy = string(cc.Data["ocicredentialConfig-tenancyId"])
 	v.PrivateKeyPassphrase = string(cc.Data["ocicredentialConfig-passphrase"])
 	v.PrivateKey = string(cc.Data["ocicredentialConfig-privateKeyContents"])
 	return nil
 }
 
+func validateAuthType(authType string) error {
+	switch authType {
+	case "", AuthTypeUserPrincipal, AuthTypeInstancePrincipal, AuthTypeWorkloadIdentity:
+		return nil
+	}
+	return fmt.Errorf("unknown authentication type %s, must be one of %s, %s or %s", authType, AuthTypeUserPrincipal, AuthTypeInstancePrincipal, AuthTypeWorkloadIdentity)
+}
+
 func (v *Variables) SetQuickCreateVCNInfo(ctx context.Context, di dynamic.Interface) error {
 	// Only set Quick Create VCN Info if using Quick Create VCN, and the VCN info is unset.
 	if v.QuickCreateVCN && v.isNetworkingUnset() {
@@ -4,7 +4,14 @@
 package variables
 
 import (
+	"context"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
 	"github.com/stretchr/testify/assert"
+	ocifake "github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/oci/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 	"testing"
 )
 
@@ -25,3 +32,276 @@ func TestParseNodePools(t *testing.T) {
 	assert.Equal(t, np1.Name, "np-1")
 	assert.Equal(t, np2.Name, "np-2")
 }
+
+func TestParseAutoscaledNodePools(t *testing.T) {
+	var tests = []struct {
+		name       string
+		nodePool   string
+		autoscaled bool
+		hasError   bool
+	}{
+		{
+			"fixed size",
+			"{\"name\":\"np-1\",\"replicas\":2}",
+			false,
+			false,
+		},
+		{
+			"autoscaled",
+			"{\"name\":\"np-1\",\"replicas\":2,\"minReplicas\":1,\"maxReplicas\":5}",
+			true,
+			false,
+		},
+		{
+			"autoscaled from zero",
+			"{\"name\":\"np-1\",\"replicas\":2,\"maxReplicas\":5}",
+			true,
+			false,
+		},
+		{
+			"not JSON",
+			"np-1",
+			false,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{
+				RawNodePools: []string{tt.nodePool},
+			}
+			nps, err := v.ParseNodePools()
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			v.NodePools = nps
+			assert.Equal(t, tt.autoscaled, v.IsAutoscaled())
+		})
+	}
+}
+
+func TestSetupOCIAuth(t *testing.T) {
+	cc := func(authType string) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "creds",
+				Namespace: "cattle-global-data",
+			},
+			Data: map[string][]byte{
+				"ocicredentialConfig-tenancyId":          []byte("tenancy"),
+				"ocicredentialConfig-userId":             []byte("user"),
+				"ocicredentialConfig-fingerprint":        []byte("fingerprint"),
+				"ocicredentialConfig-privateKeyContents": []byte("key"),
+			},
+		}
+		if authType != "" {
+			s.Data[cloudCredentialAuthTypeField] = []byte(authType)
+		}
+		return s
+	}
+	var tests = []struct {
+		name         string
+		v            *Variables
+		cc           *corev1.Secret
+		authType     string
+		hasPrincipal bool
+		hasError     bool
+	}{
+		{
+			"user principal from cloud credential",
+			&Variables{CloudCredentialId: "cattle-global-data:creds"},
+			cc(""),
+			"",
+			true,
+			false,
+		},
+		{
+			"instance principal from cloud credential",
+			&Variables{CloudCredentialId: "cattle-global-data:creds"},
+			cc(AuthTypeInstancePrincipal),
+			AuthTypeInstancePrincipal,
+			false,
+			false,
+		},
+		{
+			"driver option overrides cloud credential",
+			&Variables{CloudCredentialId: "cattle-global-data:creds", AuthType: AuthTypeWorkloadIdentity},
+			cc(AuthTypeInstancePrincipal),
+			AuthTypeWorkloadIdentity,
+			false,
+			false,
+		},
+		{
+			"instance principal without cloud credential",
+			&Variables{AuthType: AuthTypeInstancePrincipal},
+			cc(""),
+			AuthTypeInstancePrincipal,
+			false,
+			false,
+		},
+		{
+			"unknown authentication type",
+			&Variables{CloudCredentialId: "cattle-global-data:creds", AuthType: "Unknown"},
+			cc(""),
+			"Unknown",
+			false,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ki := fake.NewSimpleClientset(tt.cc)
+			err := SetupOCIAuth(context.TODO(), ki, tt.v)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.authType, tt.v.AuthType)
+			assert.Equal(t, tt.hasPrincipal, tt.v.UsesUserPrincipal())
+			assert.Equal(t, tt.hasPrincipal, tt.v.PrivateKey != "")
+		})
+	}
+}
+
+func TestSetKubernetesVersion(t *testing.T) {
+	client := &ocifake.Client{
+		KubernetesVersions: []string{"v1.25.4", "v1.26.2", "v1.27.2"},
+	}
+	var tests = []struct {
+		name     string
+		current  string
+		version  string
+		nodePool string
+		res      string
+		hasError bool
+	}{
+		{
+			"defaults to the latest version",
+			"",
+			"",
+			"",
+			"v1.27.2",
+			false,
+		},
+		{
+			"supported version",
+			"",
+			"v1.26.2",
+			"",
+			"v1.26.2",
+			false,
+		},
+		{
+			"unsupported version",
+			"",
+			"v1.24.1",
+			"",
+			"",
+			true,
+		},
+		{
+			"unchanged version is not checked",
+			"v1.24.1",
+			"v1.24.1",
+			"",
+			"v1.24.1",
+			false,
+		},
+		{
+			"empty version keeps the current version",
+			"v1.25.4",
+			"",
+			"",
+			"v1.25.4",
+			false,
+		},
+		{
+			"minor upgrade",
+			"v1.26.2",
+			"v1.27.2",
+			"",
+			"v1.27.2",
+			false,
+		},
+		{
+			"minor skip",
+			"v1.25.4",
+			"v1.27.2",
+			"",
+			"",
+			true,
+		},
+		{
+			"downgrade",
+			"v1.27.2",
+			"v1.26.2",
+			"",
+			"",
+			true,
+		},
+		{
+			"node pool within skew",
+			"",
+			"v1.27.2",
+			"v1.25.4",
+			"v1.27.2",
+			false,
+		},
+		{
+			"node pool newer than control plane",
+			"",
+			"v1.26.2",
+			"v1.27.2",
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Variables{
+				KubernetesVersion:        tt.version,
+				currentKubernetesVersion: tt.current,
+				NodePools: []NodePool{
+					{Name: "np-1", Version: tt.nodePool},
+				},
+			}
+			err := v.setKubernetesVersion(context.TODO(), client)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.res, v.KubernetesVersion)
+		})
+	}
+}
+
+func TestSetRegionInfo(t *testing.T) {
+	client := &ocifake.Client{
+		Regions: []identity.Region{
+			{Name: common.String("eu-frankfurt-1"), Key: common.String("FRA")},
+			{Name: common.String("xx-newcity-1"), Key: common.String("NEW")},
+		},
+	}
+	v := &Variables{Region: "eu-frankfurt-1"}
+	assert.NoError(t, v.setRegionInfo(context.TODO(), client))
+	assert.Equal(t, "fra", v.RegionInfo.Key)
+
+	// regions newer than the driver are looked up in OCI
+	v = &Variables{Region: "xx-newcity-1"}
+	assert.NoError(t, v.setRegionInfo(context.TODO(), client))
+	assert.Equal(t, "new", v.RegionInfo.Key)
+	assert.Equal(t, "all-new-services-in-oracle-services-network", v.RegionInfo.ServiceCIDRLabel())
+
+	v = &Variables{Region: "eu-nowhere-1"}
+	assert.Error(t, v.setRegionInfo(context.TODO(), client))
+
+	v = &Variables{QuickCreateVCN: true}
+	assert.Error(t, v.setRegionInfo(context.TODO(), client))
+}
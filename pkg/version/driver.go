@@ -0,0 +1,8 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package version
+
+// DriverVersion is the version of the driver build, set with
+// -ldflags "-X github.com/verrazzano/kontainer-engine-driver-oke-capi/pkg/version.DriverVersion=<version>"
+var DriverVersion = "dev"
@@ -0,0 +1,91 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package version
+
+import (
+	"errors"
+	"fmt"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"strings"
+)
+
+// MaxNodePoolVersionSkew is how many minor versions a node pool may be behind the control plane
+const MaxNodePoolVersionSkew = 2
+
+// LatestKubernetesVersion returns the newest of the supported Kubernetes versions
+func LatestKubernetesVersion(supported []string) (string, error) {
+	var latest *utilversion.Version
+	var latestVersion string
+	for _, s := range supported {
+		v, err := utilversion.ParseGeneric(s)
+		if err != nil {
+			continue
+		}
+		if latest == nil || latest.LessThan(v) {
+			latest = v
+			latestVersion = s
+		}
+	}
+	if latest == nil {
+		return "", errors.New("no supported Kubernetes versions found")
+	}
+	return latestVersion, nil
+}
+
+// ValidateKubernetesVersion checks that a Kubernetes version is one of the supported versions
+func ValidateKubernetesVersion(version string, supported []string) error {
+	for _, s := range supported {
+		if s == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("Kubernetes version %s is not supported, must be one of %s", version, strings.Join(supported, ", "))
+}
+
+// ValidateKubernetesUpgrade checks that the control plane can be moved from the current to the target version.
+// Downgrades and upgrades that skip a minor version are not allowed.
+func ValidateKubernetesUpgrade(current, target string) error {
+	if current == target {
+		return nil
+	}
+	currentVersion, targetVersion, err := parseVersions(current, target)
+	if err != nil {
+		return err
+	}
+	if targetVersion.LessThan(currentVersion) {
+		return fmt.Errorf("cannot downgrade Kubernetes version from %s to %s", current, target)
+	}
+	if targetVersion.Major() != currentVersion.Major() || targetVersion.Minor() > currentVersion.Minor()+1 {
+		return fmt.Errorf("cannot upgrade Kubernetes version from %s to %s, upgrades must not skip a minor version", current, target)
+	}
+	return nil
+}
+
+// ValidateNodePoolVersion checks that a node pool's version is not newer than the control plane,
+// and is at most MaxNodePoolVersionSkew minor versions behind it
+func ValidateNodePoolVersion(controlPlane, nodePool string) error {
+	controlPlaneVersion, nodePoolVersion, err := parseVersions(controlPlane, nodePool)
+	if err != nil {
+		return err
+	}
+	if controlPlaneVersion.LessThan(nodePoolVersion) {
+		return fmt.Errorf("node pool version %s must not be newer than the control plane version %s", nodePool, controlPlane)
+	}
+	if nodePoolVersion.Major() != controlPlaneVersion.Major() || nodePoolVersion.Minor()+MaxNodePoolVersionSkew < controlPlaneVersion.Minor() {
+		return fmt.Errorf("node pool version %s must be within %d minor versions of the control plane version %s", nodePool, MaxNodePoolVersionSkew, controlPlane)
+	}
+	return nil
+}
+
+func parseVersions(v1, v2 string) (*utilversion.Version, *utilversion.Version, error) {
+	version1, err := utilversion.ParseGeneric(v1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid Kubernetes version %s: %v", v1, err)
+	}
+	version2, err := utilversion.ParseGeneric(v2)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid Kubernetes version %s: %v", v2, err)
+	}
+	return version1, version2, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2023, Oracle and/or its affiliates.
+// Licensed under the Universal Permissive License v 1.0 as shown at https://oss.oracle.com/licenses/upl.
+
+package version
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var testSupportedVersions = []string{"v1.25.4", "v1.26.7", "v1.26.2", "v1.27.2"}
+
+func TestLatestKubernetesVersion(t *testing.T) {
+	latest, err := LatestKubernetesVersion(testSupportedVersions)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.27.2", latest)
+
+	_, err = LatestKubernetesVersion(nil)
+	assert.Error(t, err)
+}
+
+func TestValidateKubernetesVersion(t *testing.T) {
+	assert.NoError(t, ValidateKubernetesVersion("v1.26.2", testSupportedVersions))
+	assert.Error(t, ValidateKubernetesVersion("v1.24.1", testSupportedVersions))
+	assert.Error(t, ValidateKubernetesVersion("", testSupportedVersions))
+}
+
+func TestValidateKubernetesUpgrade(t *testing.T) {
+	var tests = []struct {
+		name     string
+		current  string
+		target   string
+		hasError bool
+	}{
+		{
+			"unchanged",
+			"v1.26.2",
+			"v1.26.2",
+			false,
+		},
+		{
+			"patch upgrade",
+			"v1.26.2",
+			"v1.26.7",
+			false,
+		},
+		{
+			"minor upgrade",
+			"v1.26.7",
+			"v1.27.2",
+			false,
+		},
+		{
+			"minor skip",
+			"v1.25.4",
+			"v1.27.2",
+			true,
+		},
+		{
+			"downgrade",
+			"v1.26.7",
+			"v1.26.2",
+			true,
+		},
+		{
+			"invalid version",
+			"v1.26.7",
+			"latest",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKubernetesUpgrade(tt.current, tt.target)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateNodePoolVersion(t *testing.T) {
+	var tests = []struct {
+		name         string
+		controlPlane string
+		nodePool     string
+		hasError     bool
+	}{
+		{
+			"same version",
+			"v1.27.2",
+			"v1.27.2",
+			false,
+		},
+		{
+			"within skew",
+			"v1.27.2",
+			"v1.25.4",
+			false,
+		},
+		{
+			"outside skew",
+			"v1.27.2",
+			"v1.24.1",
+			true,
+		},
+		{
+			"newer than control plane",
+			"v1.26.2",
+			"v1.27.2",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNodePoolVersion(tt.controlPlane, tt.nodePool)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}